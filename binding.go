@@ -2,12 +2,16 @@ package wool
 
 import (
 	"encoding"
+	"encoding/xml"
 	"errors"
-	"github.com/goccy/go-json"
+	"fmt"
 	"github.com/spf13/cast"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -16,57 +20,79 @@ var _ CtxBinding = (*DefaultCtx)(nil)
 type CtxBinding interface {
 	BindBody(i any) error
 	BindJSON(i any) error
+	BindXML(i any) error
 	BindForm(i any) error
 	BindPath(i any) error
 	BindQuery(i any) error
 	BindHeaders(i any) error
 	BindCtx(i any) error
+	StreamMultipart(s MultipartStream) error
 	Bind(i any) error
 	Validate(i any) error
 }
 
+// BindBody decodes the body with the Decoder registered for its media type,
+// see Wool.RegisterDecoder. A request without body and Content-Type is left
+// alone.
 func (c *DefaultCtx) BindBody(i any) error {
-	if c.Req().IsJSON() {
-		return c.BindJSON(i)
-	} else if c.Req().IsForm() || c.Req().IsMultipartForm() {
-		return c.BindForm(i)
-	}
-	return NewErrBadRequest(nil)
+	return c.wool.decode(c, i)
 }
 
+// BindJSON decodes the body as configured by the JSONBinding of the route or,
+// without one, of the Wool.
 func (c *DefaultCtx) BindJSON(i any) error {
-	if err := json.NewDecoder(c.Req().Body).Decode(i); err != nil {
+	cfg, ok := JSONBindingFromContext(c.Req().Context())
+	if !ok {
+		cfg = c.wool.JSONBinding
+	}
+	return cfg.decode(c, i)
+}
+
+func (c *DefaultCtx) BindXML(i any) error {
+	if err := xml.NewDecoder(c.Req().Body).Decode(i); err != nil {
 		return NewErrBadRequest(err)
 	}
 	return nil
 }
 
+// BindForm binds the form values and the uploaded files of a multipart form.
+// Files are bound to fields of type *multipart.FileHeader,
+// []*multipart.FileHeader and io.ReadCloser and can be limited with the
+// maxsize and accept tags, e.g. `form:"avatar" maxsize:"2MB" accept:"image/*"`.
 func (c *DefaultCtx) BindForm(i any) (err error) {
 	var values url.Values
 	if values, err = c.Req().FormValues(); err == nil {
-		if err = Bind(i, values, "form"); err == nil {
+		var files map[string][]*multipart.FileHeader
+		if form := c.Req().MultipartForm; form != nil {
+			files = form.File
+		}
+		if err = bind(i, values, files, "form", c.wool.BindLimits); err == nil {
 			return
 		}
+		var e *Error
+		if errors.As(err, &e) {
+			return err
+		}
 	}
 	return NewErrBadRequest(err)
 }
 
 func (c *DefaultCtx) BindPath(i any) (err error) {
-	if err = Bind(i, c.Req().PathParams(), "path"); err != nil {
+	if err = bind(i, c.Req().PathParams(), nil, "path", c.wool.BindLimits); err != nil {
 		err = NewErrBadRequest(err)
 	}
 	return
 }
 
 func (c *DefaultCtx) BindQuery(i any) (err error) {
-	if err = Bind(i, c.Req().QueryParams(), "query"); err != nil {
+	if err = bind(i, c.Req().QueryParams(), nil, "query", c.wool.BindLimits); err != nil {
 		err = NewErrBadRequest(err)
 	}
 	return
 }
 
 func (c *DefaultCtx) BindHeaders(i any) (err error) {
-	if err = Bind(i, c.Req().Header, "header"); err != nil {
+	if err = bind(i, c.Req().Header, nil, "header", c.wool.BindLimits); err != nil {
 		err = NewErrBadRequest(err)
 	}
 	return
@@ -81,7 +107,7 @@ func (c *DefaultCtx) BindCtx(i any) error {
 			}
 			return true
 		})
-		if err := Bind(i, all, "ctx"); err != nil {
+		if err := bind(i, all, nil, "ctx", c.wool.BindLimits); err != nil {
 			return NewErrBadRequest(err)
 		}
 	}
@@ -122,8 +148,62 @@ func (c *DefaultCtx) Validate(i any) error {
 	return c.wool.Validator.ValidateCtx(c.Req().Context(), i)
 }
 
+// BindLimits limit the keys Bind accepts. MaxDepth limits the segments of a
+// key, "items[0].qty" has 3, and MaxIndex the index of a slice element. Keys
+// beyond the limits are rejected. MaxElements limits the total length of the
+// slices a single Bind makes from indexed keys, which can be much larger than
+// the number of keys. A zero limit is replaced by its default.
+type BindLimits struct {
+	MaxDepth    int
+	MaxIndex    int
+	MaxElements int
+}
+
+const (
+	DefaultBindMaxDepth    = 16
+	DefaultBindMaxIndex    = 1000
+	DefaultBindMaxElements = 10000
+)
+
+// WithBindLimits sets the limits of the keys bound by the Bind methods of Ctx.
+func WithBindLimits(limits BindLimits) Option {
+	return func(w *Wool) {
+		w.BindLimits = limits
+	}
+}
+
+func (l BindLimits) withDefaults() BindLimits {
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = DefaultBindMaxDepth
+	}
+	if l.MaxIndex <= 0 {
+		l.MaxIndex = DefaultBindMaxIndex
+	}
+	if l.MaxElements <= 0 {
+		l.MaxElements = DefaultBindMaxElements
+	}
+	return l
+}
+
+// Bind binds data to the fields of destination that are tagged with tag. Keys
+// address nested fields with dotted paths and brackets: "user.name" and
+// "user[name]" bind the field tagged "name" of the struct field tagged "user",
+// "items[0].qty" an element of a slice of structs, "tags[]" appends to a slice
+// and "meta[color]" sets a map entry. Untagged struct fields are bound from
+// the same keys as their parent.
+//
+// A tagged field whose key is missing is set to the value of its default tag,
+// e.g. `query:"page" default:"1"`, unless it is already set. The default of a
+// slice is split at commas. A struct with a field of type Presence records
+// which of its fields were present.
+//
+// Bind uses the default BindLimits, the Bind methods of Ctx those of the Wool.
 func Bind(destination any, data map[string][]string, tag string) error {
-	if destination == nil || len(data) == 0 {
+	return bind(destination, data, nil, tag, BindLimits{})
+}
+
+func bind(destination any, data map[string][]string, files map[string][]*multipart.FileHeader, tag string, limits BindLimits) error {
+	if destination == nil {
 		return nil
 	}
 	typ := reflect.TypeOf(destination).Elem()
@@ -137,75 +217,350 @@ func Bind(destination any, data map[string][]string, tag string) error {
 	}
 
 	if typ.Kind() != reflect.Struct {
-		if tag == "path" || tag == "query" || tag == "header" {
+		if len(data) == 0 || tag == "path" || tag == "query" || tag == "header" {
 			return nil
 		}
 		return errors.New("binding element must be a struct")
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		typeField := typ.Field(i)
-		structField := val.Field(i)
-		if typeField.Anonymous {
-			if structField.Kind() == reflect.Ptr {
-				structField = structField.Elem()
-			}
+	plan := bindPlanOf(typ, tag)
+	if plan.err != nil {
+		return plan.err
+	}
+	if len(files) == 0 && isFlat(data) {
+		return plan.bindFlat(val, data, tag)
+	}
+
+	root, err := newBindNode(data, files, limits.withDefaults())
+	if err != nil {
+		return err
+	}
+	if _, err = bindStruct(val, root, tag); err != nil {
+		root.state.closeFiles()
+		return err
+	}
+	return nil
+}
+
+// isFlat reports whether data has no nested keys such as "user.name" or
+// "tags[]".
+func isFlat(data map[string][]string) bool {
+	for key := range data {
+		if strings.ContainsAny(key, ".[") {
+			return false
 		}
-		if !structField.CanSet() {
-			continue
+	}
+	return true
+}
+
+// bindNode is a key of the bound data split into its path segments, it holds
+// the values of the key and the nested keys below it.
+type bindNode struct {
+	values   []string
+	files    []*multipart.FileHeader
+	children map[string]*bindNode
+	state    *bindState
+}
+
+// bindState is shared by the nodes of a single Bind call.
+type bindState struct {
+	limits   BindLimits
+	elements int
+	opened   []io.Closer
+}
+
+// reserve takes n slice elements from the limit of the Bind call.
+func (s *bindState) reserve(n int) error {
+	if s == nil {
+		return nil
+	}
+	if s.elements -= n; s.elements < 0 {
+		return fmt.Errorf("slices exceed the maximum of %d elements", s.limits.MaxElements)
+	}
+	return nil
+}
+
+func newBindNode(data map[string][]string, files map[string][]*multipart.FileHeader, limits BindLimits) (*bindNode, error) {
+	root := &bindNode{state: &bindState{limits: limits, elements: limits.MaxElements}}
+	for key, values := range data {
+		n, err := root.insert(key)
+		if err != nil {
+			return nil, err
 		}
-		structFieldKind := structField.Kind()
-		inputFieldName := typeField.Tag.Get(tag)
-		if typeField.Anonymous && structField.Kind() == reflect.Struct && inputFieldName != "" {
-			return errors.New("query/path/form tags are not allowed with anonymous struct field")
+		n.values = append(n.values, values...)
+	}
+	for key, headers := range files {
+		n, err := root.insert(key)
+		if err != nil {
+			return nil, err
 		}
+		n.files = append(n.files, headers...)
+	}
+	return root, nil
+}
 
-		if inputFieldName == "" {
-			if structFieldKind == reflect.Struct {
-				if err := Bind(structField.Addr().Interface(), data, tag); err != nil {
-					return err
-				}
+func (n *bindNode) insert(key string) (*bindNode, error) {
+	path := splitBindKey(key)
+	if maxDepth := n.state.limits.MaxDepth; len(path) > maxDepth {
+		return nil, fmt.Errorf("key %q is nested deeper than %d levels", key, maxDepth)
+	}
+
+	for _, s := range path {
+		child, ok := n.children[s]
+		if !ok {
+			if n.children == nil {
+				n.children = map[string]*bindNode{}
 			}
-			continue
+			child = &bindNode{state: n.state}
+			n.children[s] = child
 		}
+		n = child
+	}
+	return n, nil
+}
 
-		inputValue, exists := data[inputFieldName]
-		if !exists {
-			for k, v := range data {
-				if strings.EqualFold(k, inputFieldName) {
-					inputValue = v
-					exists = true
+// lookup returns the node of path, ignoring the case when there is no exact
+// match.
+func (n *bindNode) lookup(path []string) *bindNode {
+	for _, s := range path {
+		next, ok := n.children[s]
+		if !ok {
+			for k, child := range n.children {
+				if strings.EqualFold(k, s) {
+					next, ok = child, true
 					break
 				}
 			}
 		}
+		if !ok {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// splitBindKey splits "items[0].qty" into "items", "0" and "qty", and "tags[]"
+// into "tags" and "". A key that is not well-formed, such as "...", is a
+// single segment.
+func splitBindKey(key string) []string {
+	i := strings.IndexAny(key, ".[")
+	if i <= 0 {
+		return []string{key}
+	}
+
+	path := []string{key[:i]}
+	rest := key[i:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			j := strings.IndexAny(rest, ".[")
+			if j < 0 {
+				j = len(rest)
+			}
+			if j == 0 {
+				return []string{key}
+			}
+			path = append(path, rest[:j])
+			rest = rest[j:]
+		case '[':
+			j := strings.IndexByte(rest, ']')
+			if j < 0 || (j == 1 && len(rest) > 2) {
+				return []string{key}
+			}
+			path = append(path, rest[1:j])
+			rest = rest[j+1:]
+		default:
+			return []string{key}
+		}
+	}
+	return path
+}
+
+// Presence is the set of the names of the fields of a struct that Bind has
+// bound a value or a file to, as opposed to fields that kept their value or
+// got their default. A struct field is present when one of its fields is. It allows PATCH handlers to update only the fields that were sent.
+type Presence map[string]struct{}
+
+var presenceType = reflect.TypeOf(Presence(nil))
+
+func (p Presence) Has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
 
-		if !exists {
+// bindStruct reports whether a value or a file was bound to a field of val,
+// only such fields are recorded as present.
+func bindStruct(val reflect.Value, n *bindNode, tag string) (bool, error) {
+	plan := bindPlanOf(val.Type(), tag)
+	if plan.err != nil {
+		return false, plan.err
+	}
+
+	bound := false
+	for i := range plan.fields {
+		f := &plan.fields[i]
+		field, ok := fieldByIndex(val, f.index)
+		if !ok {
 			continue
 		}
 
-		if ok, err := unmarshalField(typeField.Type.Kind(), inputValue[0], structField); ok {
-			if err != nil {
-				return err
+		child := n.lookup(f.path)
+		if child == nil {
+			if err := f.setDefault(field, tag); err != nil {
+				return false, err
 			}
 			continue
 		}
 
-		numElems := len(inputValue)
-		if structFieldKind == reflect.Slice && numElems > 0 {
-			sliceOf := structField.Type().Elem().Kind()
-			slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
-			for j := 0; j < numElems; j++ {
-				if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
-					return err
-				}
-			}
-			val.Field(i).Set(slice)
-		} else if err := setWithProperType(typeField.Type.Kind(), inputValue[0], structField); err != nil {
-			return err
+		var (
+			fieldBound bool
+			err        error
+		)
+		if f.mode == bindFiles {
+			fieldBound, err = bindFile(field, child, f.structField)
+		} else {
+			fieldBound, err = bindValue(field, child, tag)
+		}
+		if err != nil {
+			return false, err
+		}
+		if fieldBound {
+			f.markPresent(val)
+			bound = true
 		}
 	}
-	return nil
+	return bound, nil
+}
+
+// bindValue reports whether something of n was bound to field.
+func bindValue(field reflect.Value, n *bindNode, tag string) (bool, error) {
+	if len(n.values) > 0 {
+		if ok, err := unmarshalField(field.Kind(), n.values[0], field); ok {
+			return err == nil, err
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return bindValue(field.Elem(), n, tag)
+	case reflect.Struct:
+		return bindStruct(field, n, tag)
+	case reflect.Slice:
+		return bindSlice(field, n, tag)
+	case reflect.Map:
+		return bindMap(field, n, tag)
+	}
+
+	if len(n.values) == 0 {
+		return false, nil
+	}
+	return true, setWithProperType(field.Kind(), n.values[0], field)
+}
+
+// bindSlice binds the values of repeated and "key[]" keys, or the elements of
+// indexed keys. Both cannot be mixed. Struct elements between sparse indexes
+// get their defaults.
+func bindSlice(field reflect.Value, n *bindNode, tag string) (bool, error) {
+	values := n.values
+	if appended, ok := n.children[""]; ok {
+		values = append(values[:len(values):len(values)], appended.values...)
+	}
+
+	length := len(values)
+	indexes := 0
+	for key := range n.children {
+		if key == "" {
+			continue
+		}
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 {
+			return false, fmt.Errorf("invalid index %q", key)
+		}
+		if maxIndex := n.state.limits.MaxIndex; index > maxIndex {
+			return false, fmt.Errorf("index %d exceeds the maximum of %d", index, maxIndex)
+		}
+		if len(values) > 0 {
+			return false, fmt.Errorf("index %d cannot be mixed with repeated values", index)
+		}
+		if index >= length {
+			length = index + 1
+		}
+		indexes++
+	}
+
+	if length == 0 {
+		return false, nil
+	}
+	if err := n.state.reserve(length); err != nil {
+		return false, err
+	}
+
+	slice := reflect.MakeSlice(field.Type(), length, length)
+	for i, value := range values {
+		if _, err := bindValue(slice.Index(i), &bindNode{values: []string{value}, state: n.state}, tag); err != nil {
+			return false, err
+		}
+	}
+
+	var bound []bool
+	if indexes < length && field.Type().Elem().Kind() == reflect.Struct {
+		bound = make([]bool, length)
+	}
+	for key, child := range n.children {
+		if key == "" {
+			continue
+		}
+		index, _ := strconv.Atoi(key)
+		if _, err := bindValue(slice.Index(index), child, tag); err != nil {
+			return false, err
+		}
+		if bound != nil {
+			bound[index] = true
+		}
+	}
+	for i := range bound {
+		if bound[i] {
+			continue
+		}
+		if _, err := bindStruct(slice.Index(i), emptyBindNode, tag); err != nil {
+			return false, err
+		}
+	}
+
+	field.Set(slice)
+	return true, nil
+}
+
+func bindMap(field reflect.Value, n *bindNode, tag string) (bool, error) {
+	if len(n.children) == 0 {
+		return false, nil
+	}
+	if field.IsNil() {
+		field.Set(reflect.MakeMapWithSize(field.Type(), len(n.children)))
+	}
+
+	keyType, elemType := field.Type().Key(), field.Type().Elem()
+	for key, child := range n.children {
+		k := reflect.New(keyType).Elem()
+		if err := setWithProperType(keyType.Kind(), key, k); err != nil {
+			return false, err
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if existing := field.MapIndex(k); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if _, err := bindValue(elem, child, tag); err != nil {
+			return false, err
+		}
+		field.SetMapIndex(k, elem)
+	}
+	return true, nil
 }
 
 func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
@@ -217,25 +572,25 @@ func setWithProperType(valueKind reflect.Kind, val string, structField reflect.V
 	case reflect.Ptr:
 		return setWithProperType(structField.Elem().Kind(), val, structField.Elem())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := cast.ToInt64E(val)
+		v, err := strconv.ParseInt(trimZeroDecimal(val), 0, 64)
 		if err != nil {
 			return err
 		}
 		structField.SetInt(v)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := cast.ToUint64E(val)
+		v, err := strconv.ParseUint(trimZeroDecimal(val), 0, 64)
 		if err != nil {
 			return err
 		}
 		structField.SetUint(v)
 	case reflect.Bool:
-		v, err := cast.ToBoolE(val)
+		v, err := strconv.ParseBool(val)
 		if err != nil {
 			return err
 		}
 		structField.SetBool(v)
 	case reflect.Float32, reflect.Float64:
-		v, err := cast.ToFloat64E(val)
+		v, err := strconv.ParseFloat(val, 64)
 		if err != nil {
 			return err
 		}
@@ -248,6 +603,24 @@ func setWithProperType(valueKind reflect.Kind, val string, structField reflect.V
 	return nil
 }
 
+// trimZeroDecimal accepts integers with a zero fraction, "10.00" is "10".
+func trimZeroDecimal(s string) string {
+	var foundZero bool
+	for i := len(s); i > 0; i-- {
+		switch s[i-1] {
+		case '.':
+			if foundZero {
+				return s[:i-1]
+			}
+		case '0':
+			foundZero = true
+		default:
+			return s
+		}
+	}
+	return s
+}
+
 func unmarshalField(valueKind reflect.Kind, val string, field reflect.Value) (bool, error) {
 	switch valueKind {
 	case reflect.Ptr:
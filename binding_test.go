@@ -0,0 +1,321 @@
+package wool
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestBindMaxElements(t *testing.T) {
+	type row struct {
+		C []int `query:"c"`
+	}
+	type rows struct {
+		R []row `query:"r"`
+	}
+
+	tests := []struct {
+		name string
+		rows int
+		ok   bool
+	}{
+		{"within", 9, true},
+		{"exceeded", 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string][]string{}
+			for i := 0; i < tt.rows; i++ {
+				data["r["+strconv.Itoa(i)+"].c[1000]"] = []string{"1"}
+			}
+
+			var dest rows
+			err := Bind(&dest, data, "query")
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %t", err, tt.ok)
+			}
+			if tt.ok && (len(dest.R) != tt.rows || dest.R[0].C[1000] != 1) {
+				t.Errorf("bound %d rows, want %d", len(dest.R), tt.rows)
+			}
+		})
+	}
+}
+
+func TestBindSlice(t *testing.T) {
+	type item struct {
+		Name string `query:"name" default:"none"`
+		Qty  int    `query:"qty"`
+	}
+	type dest struct {
+		Tags  []string `query:"tags"`
+		Items []item   `query:"items"`
+	}
+
+	tests := []struct {
+		name  string
+		data  map[string][]string
+		tags  []string
+		items []item
+		ok    bool
+	}{
+		{"repeated", map[string][]string{"tags": {"a", "b"}, "tags[]": {"c"}}, []string{"a", "b", "c"}, nil, true},
+		{"indexed", map[string][]string{"tags[1]": {"b"}, "tags[0]": {"a"}}, []string{"a", "b"}, nil, true},
+		{"repeated and indexed", map[string][]string{"tags": {"b"}, "tags[0]": {"a"}}, nil, nil, false},
+		{"appended and indexed", map[string][]string{"tags[]": {"b"}, "tags[1]": {"a"}}, nil, nil, false},
+		{
+			"sparse defaults",
+			map[string][]string{"items[0].name": {"a"}, "items[2].qty": {"3"}},
+			nil,
+			[]item{{Name: "a"}, {Name: "none"}, {Name: "none", Qty: 3}},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d dest
+			err := Bind(&d, tt.data, "query")
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %t", err, tt.ok)
+			}
+			if !reflect.DeepEqual(d.Tags, tt.tags) {
+				t.Errorf("tags = %q, want %q", d.Tags, tt.tags)
+			}
+			if !reflect.DeepEqual(d.Items, tt.items) {
+				t.Errorf("items = %+v, want %+v", d.Items, tt.items)
+			}
+		})
+	}
+}
+
+func TestBindLimits(t *testing.T) {
+	type dest struct {
+		Tags []string `query:"tags"`
+		User struct {
+			Name string `query:"name"`
+		} `query:"user"`
+	}
+
+	w := newTestWool()
+	w.BindLimits = BindLimits{MaxDepth: 2, MaxIndex: 2, MaxElements: 2}
+	w.GET("/", func(c Ctx) error {
+		var d dest
+		if err := c.BindQuery(&d); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%d", len(d.Tags))
+	})
+
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"tags[1]=a", http.StatusOK},
+		{"tags[2]=a", http.StatusBadRequest},
+		{"tags[3]=a", http.StatusBadRequest},
+		{"user.name=a", http.StatusOK},
+		{"user.name.x=a", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if rec := serve(w, http.MethodGet, "/?"+tt.query); rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+		})
+	}
+
+	// the package level Bind keeps the defaults
+	var d dest
+	if err := Bind(&d, map[string][]string{"tags[3]": {"a"}, "user.name": {"a"}}, "query"); err != nil {
+		t.Errorf("Bind: %v", err)
+	}
+}
+
+func TestBindDefaults(t *testing.T) {
+	type dest struct {
+		Page     int      `query:"page" default:"1"`
+		Tags     []string `query:"tags" default:"a,b"`
+		Presence Presence
+	}
+
+	tests := []struct {
+		name    string
+		data    map[string][]string
+		page    int
+		tags    []string
+		present []string
+	}{
+		{"missing", map[string][]string{}, 1, []string{"a", "b"}, nil},
+		{"sent as zero", map[string][]string{"page": {"0"}, "tags": {""}}, 0, []string{""}, []string{"Page", "Tags"}},
+		{"sent", map[string][]string{"page": {"2"}, "tags[]": {"c"}}, 2, []string{"c"}, []string{"Page", "Tags"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d dest
+			if err := Bind(&d, tt.data, "query"); err != nil {
+				t.Fatal(err)
+			}
+			if d.Page != tt.page {
+				t.Errorf("page = %d, want %d", d.Page, tt.page)
+			}
+			if !reflect.DeepEqual(d.Tags, tt.tags) {
+				t.Errorf("tags = %q, want %q", d.Tags, tt.tags)
+			}
+			if len(d.Presence) != len(tt.present) {
+				t.Errorf("presence = %v, want %v", d.Presence, tt.present)
+			}
+			for _, name := range tt.present {
+				if !d.Presence.Has(name) {
+					t.Errorf("presence = %v, want %s", d.Presence, name)
+				}
+			}
+		})
+	}
+}
+
+func TestBindNestedPresence(t *testing.T) {
+	type user struct {
+		Name     string `query:"name"`
+		Age      int    `query:"age" default:"18"`
+		Presence Presence
+	}
+	type dest struct {
+		User     user `query:"user"`
+		Presence Presence
+	}
+
+	tests := []struct {
+		name string
+		data map[string][]string
+		user bool
+		pres []string
+	}{
+		{"missing", map[string][]string{}, false, nil},
+		{"nothing bound", map[string][]string{"user.name.x": {"z"}}, false, nil},
+		{"nested", map[string][]string{"user.name": {"bob"}}, true, []string{"Name"}},
+		{"nested zero", map[string][]string{"user[age]": {"0"}}, true, []string{"Age"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d dest
+			if err := Bind(&d, tt.data, "query"); err != nil {
+				t.Fatal(err)
+			}
+			if got := d.Presence.Has("User"); got != tt.user {
+				t.Errorf("user present = %t, want %t", got, tt.user)
+			}
+			if len(d.User.Presence) != len(tt.pres) {
+				t.Errorf("user presence = %v, want %v", d.User.Presence, tt.pres)
+			}
+			for _, name := range tt.pres {
+				if !d.User.Presence.Has(name) {
+					t.Errorf("user presence = %v, want %s", d.User.Presence, name)
+				}
+			}
+		})
+	}
+}
+
+type CyclicSelf struct {
+	*CyclicSelf
+	X int `query:"x"`
+}
+
+type CyclicOuter struct {
+	Inner CyclicInner `query:"inner"`
+	Y     int         `query:"y" default:"2"`
+}
+
+type CyclicInner struct {
+	*CyclicOuter
+	Z int `query:"z" default:"3"`
+}
+
+func TestBindCyclicEmbedding(t *testing.T) {
+	var self CyclicSelf
+	if err := Bind(&self, map[string][]string{"x": {"1"}}, "query"); err != nil || self.X != 1 {
+		t.Errorf("self: x = %d, err = %v", self.X, err)
+	}
+
+	var outer CyclicOuter
+	if err := Bind(&outer, map[string][]string{"inner.z": {"4"}}, "query"); err != nil {
+		t.Fatal(err)
+	}
+	if outer.Inner.Z != 4 || outer.Y != 2 {
+		t.Errorf("outer: inner.z = %d, y = %d, want 4 and 2", outer.Inner.Z, outer.Y)
+	}
+}
+
+type benchQuery struct {
+	Page   int      `query:"page" default:"1"`
+	Size   int      `query:"size"`
+	Sort   string   `query:"sort"`
+	Tags   []string `query:"tags"`
+	Active bool     `query:"active"`
+	From   string   `query:"from"`
+}
+
+type benchHeaders struct {
+	RequestID string `header:"X-Request-Id"`
+	Agent     string `header:"User-Agent"`
+	Language  string `header:"Accept-Language"`
+}
+
+type benchForm struct {
+	User struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	} `form:"user"`
+	Items []struct {
+		Qty int `form:"qty"`
+	} `form:"items"`
+}
+
+func BenchmarkBindQuery(b *testing.B) {
+	data := map[string][]string{
+		"page":   {"2"},
+		"size":   {"20"},
+		"sort":   {"name"},
+		"tags":   {"a", "b"},
+		"active": {"true"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var dest benchQuery
+		if err := Bind(&dest, data, "query"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindHeaders(b *testing.B) {
+	header := http.Header{}
+	header.Set("X-Request-Id", "abc")
+	header.Set("User-Agent", "go")
+	header.Set("Accept-Language", "en")
+	header.Set("Accept", "*/*")
+	header.Set("Accept-Encoding", "gzip")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var dest benchHeaders
+		if err := Bind(&dest, header, "header"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindNested(b *testing.B) {
+	data := map[string][]string{
+		"user.name":    {"bob"},
+		"user[age]":    {"30"},
+		"items[0].qty": {"1"},
+		"items[1].qty": {"2"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var dest benchForm
+		if err := Bind(&dest, data, "form"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
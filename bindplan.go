@@ -0,0 +1,350 @@
+package wool
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// bindPlans caches a *bindPlan per struct type and tag.
+var bindPlans sync.Map
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	presentValue        = reflect.ValueOf(struct{}{})
+	emptyBindNode       = &bindNode{}
+)
+
+type bindPlanKey struct {
+	typ reflect.Type
+	tag string
+}
+
+type bindMode uint8
+
+const (
+	bindOther bindMode = iota
+	bindText
+	bindScalar
+	bindScalars
+	bindFiles
+)
+
+// bindPlan is the list of the fields of a struct type that are bound for a tag,
+// including the fields of untagged and embedded structs. byLower indexes the
+// fields by their lower case key for keys that differ in case.
+type bindPlan struct {
+	fields      []bindField
+	byLower     map[string][]int
+	hasDefaults bool
+	err         error
+}
+
+type bindField struct {
+	structField    reflect.StructField
+	index          []int
+	presence       []int
+	name           reflect.Value
+	key            string
+	path           []string
+	mode           bindMode
+	kind           reflect.Kind
+	elemKind       reflect.Kind
+	defaults       []string
+	hasDefault     bool
+	nestedDefaults bool
+}
+
+func bindPlanOf(typ reflect.Type, tag string) *bindPlan {
+	key := bindPlanKey{typ: typ, tag: tag}
+	if plan, ok := bindPlans.Load(key); ok {
+		return plan.(*bindPlan)
+	}
+	plan, _ := bindPlans.LoadOrStore(key, newBindPlan(typ, tag, map[reflect.Type]bool{}))
+	return plan.(*bindPlan)
+}
+
+// nestedBindPlan returns the plan of a struct type nested in a plan that is
+// being built, or nil when typ is already being built further up, e.g. for
+// "type A struct{ *A }". Such a plan depends on the stack it was built on and
+// is not cached.
+func nestedBindPlan(typ reflect.Type, tag string, building map[reflect.Type]bool) *bindPlan {
+	if plan, ok := bindPlans.Load(bindPlanKey{typ: typ, tag: tag}); ok {
+		return plan.(*bindPlan)
+	}
+	if building[typ] {
+		return nil
+	}
+	return newBindPlan(typ, tag, building)
+}
+
+func newBindPlan(typ reflect.Type, tag string, building map[reflect.Type]bool) *bindPlan {
+	plan := &bindPlan{byLower: map[string][]int{}}
+	building[typ] = true
+	defer delete(building, typ)
+
+	var presence []int
+	for i := 0; i < typ.NumField(); i++ {
+		if sf := typ.Field(i); sf.Type == presenceType && sf.IsExported() {
+			presence = []int{i}
+		}
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		fieldType := sf.Type
+		if sf.Anonymous && fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		key := sf.Tag.Get(tag)
+
+		if sf.Anonymous && fieldType.Kind() == reflect.Struct && key != "" {
+			plan.err = errors.New("query/path/form tags are not allowed with anonymous struct field")
+			return plan
+		}
+
+		if key == "" {
+			if fieldType.Kind() == reflect.Struct {
+				nested := nestedBindPlan(fieldType, tag, building)
+				if nested == nil {
+					continue
+				}
+				if nested.err != nil {
+					plan.err = nested.err
+					return plan
+				}
+				for _, f := range nested.fields {
+					f.index = append([]int{i}, f.index...)
+					if f.presence != nil {
+						f.presence = append([]int{i}, f.presence...)
+					}
+					plan.add(f)
+				}
+			}
+			continue
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Ptr {
+			continue
+		}
+
+		f := bindField{
+			structField: sf,
+			index:       []int{i},
+			presence:    presence,
+			name:        reflect.ValueOf(sf.Name),
+			key:         key,
+			path:        splitBindKey(key),
+			mode:        bindModeOf(sf.Type),
+			kind:        sf.Type.Kind(),
+		}
+		if f.kind == reflect.Slice {
+			f.elemKind = sf.Type.Elem().Kind()
+		}
+		if value, ok := sf.Tag.Lookup("default"); ok {
+			f.hasDefault = true
+			if f.kind == reflect.Slice {
+				f.defaults = strings.Split(value, ",")
+			} else {
+				f.defaults = []string{value}
+			}
+		} else if f.kind == reflect.Struct && f.mode == bindOther {
+			nested := nestedBindPlan(sf.Type, tag, building)
+			f.nestedDefaults = nested != nil && nested.hasDefaults
+		}
+		plan.add(f)
+	}
+	return plan
+}
+
+func (p *bindPlan) add(f bindField) {
+	if len(f.path) == 1 {
+		lower := strings.ToLower(f.key)
+		p.byLower[lower] = append(p.byLower[lower], len(p.fields))
+	}
+	p.hasDefaults = p.hasDefaults || f.hasDefault || f.nestedDefaults
+	p.fields = append(p.fields, f)
+}
+
+func bindModeOf(typ reflect.Type) bindMode {
+	switch {
+	case isFileType(typ):
+		return bindFiles
+	case typ.Kind() == reflect.Ptr && typ.Implements(textUnmarshalerType),
+		typ.Kind() != reflect.Ptr && reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		return bindText
+	case isScalarKind(typ.Kind()):
+		return bindScalar
+	case typ.Kind() == reflect.Slice && (isScalarKind(typ.Elem().Kind()) || reflect.PointerTo(typ.Elem()).Implements(textUnmarshalerType)):
+		return bindScalars
+	}
+	return bindOther
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Bool, reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// foldedIndexes returns the fields whose key equals key ignoring the case. ASCII
+// keys are lowered on the stack.
+func (p *bindPlan) foldedIndexes(key string) []int {
+	var buf [64]byte
+	if len(key) > len(buf) {
+		return p.byLower[strings.ToLower(key)]
+	}
+	b := buf[:len(key)]
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= utf8.RuneSelf {
+			return p.byLower[strings.ToLower(key)]
+		}
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b[i] = c
+	}
+	return p.byLower[string(b)]
+}
+
+// bindFlat binds data without nested keys straight from the map.
+func (p *bindPlan) bindFlat(val reflect.Value, data map[string][]string, tag string) error {
+	var (
+		foldedBuf [16]string
+		folded    []string
+	)
+	for key := range data {
+		for _, i := range p.foldedIndexes(key) {
+			if _, exact := data[p.fields[i].key]; exact {
+				continue
+			}
+			if folded == nil {
+				if len(p.fields) <= len(foldedBuf) {
+					folded = foldedBuf[:len(p.fields)]
+				} else {
+					folded = make([]string, len(p.fields))
+				}
+			}
+			if folded[i] == "" {
+				folded[i] = key
+			}
+		}
+	}
+
+	for i := range p.fields {
+		f := &p.fields[i]
+		field, ok := fieldByIndex(val, f.index)
+		if !ok {
+			continue
+		}
+
+		values, present := data[f.key]
+		if !present && folded != nil && folded[i] != "" {
+			values, present = data[folded[i]], true
+		}
+		if !present {
+			if err := f.setDefault(field, tag); err != nil {
+				return err
+			}
+			continue
+		}
+
+		bound, err := f.setValues(field, values, tag)
+		if err != nil {
+			return err
+		}
+		if bound {
+			f.markPresent(val)
+		}
+	}
+	return nil
+}
+
+// setValues reports whether values were bound to field.
+func (f *bindField) setValues(field reflect.Value, values []string, tag string) (bool, error) {
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	switch f.mode {
+	case bindText:
+		_, err := unmarshalField(f.kind, values[0], field)
+		return err == nil, err
+	case bindScalar:
+		return true, setWithProperType(f.kind, values[0], field)
+	case bindScalars:
+		// a new backing array is grown in place, unlike reflect.MakeSlice the
+		// slice header is not allocated
+		field.SetZero()
+		field.Grow(len(values))
+		field.SetLen(len(values))
+		for i, value := range values {
+			if err := setWithProperType(f.elemKind, value, field.Index(i)); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case bindFiles:
+		return false, nil
+	}
+	return bindValue(field, &bindNode{values: values}, tag)
+}
+
+// setDefault sets a field that has no key to its default, or the fields of a
+// nested struct to theirs.
+func (f *bindField) setDefault(field reflect.Value, tag string) error {
+	switch {
+	case f.hasDefault:
+		if !field.IsZero() {
+			return nil
+		}
+		if _, err := f.setValues(field, f.defaults, tag); err != nil {
+			return fmt.Errorf("default of %s: %w", f.structField.Name, err)
+		}
+	case f.nestedDefaults:
+		_, err := bindStruct(field, emptyBindNode, tag)
+		return err
+	}
+	return nil
+}
+
+func (f *bindField) markPresent(val reflect.Value) {
+	if f.presence == nil {
+		return
+	}
+	presence, ok := fieldByIndex(val, f.presence)
+	if !ok {
+		return
+	}
+	if presence.IsNil() {
+		presence.Set(reflect.MakeMap(presenceType))
+	}
+	presence.SetMapIndex(f.name, presentValue)
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that reports embedded nil
+// pointers instead of panicking.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
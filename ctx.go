@@ -18,6 +18,7 @@ type Ctx interface {
 	SetRes(r Response)
 	Reset(r *http.Request, w http.ResponseWriter)
 	NegotiateFormat(offered ...string) string
+	URLFor(name string, params ...any) (string, error)
 }
 
 type DefaultCtx struct {
@@ -82,33 +83,15 @@ func (c *DefaultCtx) SetRes(res Response) {
 }
 
 func (c *DefaultCtx) Reset(r *http.Request, w http.ResponseWriter) {
-	c.req = &Request{Request: r}
+	c.req = &Request{Request: r, maxMemory: c.wool.MaxMultipartMemory}
 	c.res = NewResponse(w, c.wool.Log)
 	c.store = nil
 }
 
+func (c *DefaultCtx) URLFor(name string, params ...any) (string, error) {
+	return c.wool.URL(name, params...)
+}
+
 func (c *DefaultCtx) NegotiateFormat(offered ...string) string {
-	if len(offered) == 0 {
-		return ""
-	}
-	if len(c.Req().Accept()) == 0 {
-		return offered[0]
-	}
-	for _, accepted := range c.Req().Accept() {
-		for _, offer := range offered {
-			i := 0
-			for ; i < len(accepted); i++ {
-				if accepted[i] == '*' || offer[i] == '*' {
-					return offer
-				}
-				if accepted[i] != offer[i] {
-					break
-				}
-			}
-			if i == len(accepted) {
-				return offer
-			}
-		}
-	}
-	return ""
+	return c.Req().AcceptsTypes(offered...)
 }
@@ -0,0 +1,132 @@
+package wool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Decoder decodes the body of the request of c into i.
+type Decoder func(c Ctx, i any) error
+
+// DefaultDecoders are the decoders every Wool starts with. A key starting with
+// "+" is a structured syntax suffix, "+json" decodes every media type such as
+// "application/problem+json" that has no decoder of its own.
+var DefaultDecoders = map[string]Decoder{
+	MIMEApplicationJSON: func(c Ctx, i any) error {
+		return c.BindJSON(i)
+	},
+	"+json": func(c Ctx, i any) error {
+		return c.BindJSON(i)
+	},
+	MIMEApplicationXML: func(c Ctx, i any) error {
+		return c.BindXML(i)
+	},
+	MIMETextXML: func(c Ctx, i any) error {
+		return c.BindXML(i)
+	},
+	"+xml": func(c Ctx, i any) error {
+		return c.BindXML(i)
+	},
+	MIMEApplicationForm: func(c Ctx, i any) error {
+		return c.BindForm(i)
+	},
+	MIMEMultipartForm: func(c Ctx, i any) error {
+		return c.BindForm(i)
+	},
+}
+
+type decoders struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+}
+
+func newDecoders() *decoders {
+	d := &decoders{decoders: make(map[string]Decoder, len(DefaultDecoders))}
+	for mediaType, decoder := range DefaultDecoders {
+		d.decoders[mediaType] = decoder
+	}
+	return d
+}
+
+func (d *decoders) add(mediaType string, decoder Decoder) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.decoders[strings.ToLower(mediaType)] = decoder
+}
+
+func (d *decoders) lookup(mediaType string) (Decoder, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if decoder, ok := d.decoders[mediaType]; ok {
+		return decoder, true
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		decoder, ok := d.decoders[mediaType[i:]]
+		return decoder, ok
+	}
+	return nil, false
+}
+
+// mediaTypes returns the registered media types without the suffixes.
+func (d *decoders) mediaTypes() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	mediaTypes := make([]string, 0, len(d.decoders))
+	for mediaType := range d.decoders {
+		if !strings.HasPrefix(mediaType, "+") {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+func WithDecoder(mediaType string, decoder Decoder) Option {
+	return func(w *Wool) {
+		w.RegisterDecoder(mediaType, decoder)
+	}
+}
+
+// RegisterDecoder makes BindBody decode bodies of mediaType with decoder. The
+// mediaType can also be a suffix such as "+json" or "+cbor".
+func (wool *Wool) RegisterDecoder(mediaType string, decoder Decoder) {
+	wool.decoders.add(mediaType, decoder)
+}
+
+// decode decodes the body with the decoder registered for its media type. A
+// body of an unsupported media type is answered with 415 Unsupported Media
+// Type and the supported types in Accept-Post or Accept-Patch.
+func (wool *Wool) decode(c Ctx, i any) error {
+	req := c.Req()
+	mediaType := strings.ToLower(req.ContentType())
+	if mediaType == "" && (req.Body == nil || req.Body == http.NoBody) {
+		return nil
+	}
+
+	decoder, ok := wool.decoders.lookup(mediaType)
+	if !ok {
+		switch req.Method {
+		case http.MethodPost:
+			c.Res().Header().Set(HeaderAcceptPost, strings.Join(wool.decoders.mediaTypes(), ", "))
+		case http.MethodPatch:
+			c.Res().Header().Set(HeaderAcceptPatch, strings.Join(wool.decoders.mediaTypes(), ", "))
+		}
+		return NewErrUnsupportedMediaType(fmt.Errorf("unsupported media type %q", mediaType))
+	}
+
+	if err := decoder(c, i); err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			return err
+		}
+		return NewErrBadRequest(err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package wool
+
+import (
+	"bytes"
+	"github.com/gowool/wool/render"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Encoder returns the render.Render that encodes v in its media type.
+type Encoder func(c Ctx, v any) render.Render
+
+type encoders struct {
+	mu         sync.RWMutex
+	mediaTypes []string
+	encoders   map[string]Encoder
+}
+
+// newEncoders registers JSON and XML, JSON is preferred when the client has no
+// preference.
+func newEncoders(wool *Wool) *encoders {
+	e := &encoders{encoders: map[string]Encoder{}}
+	e.add(MIMEApplicationJSON, func(_ Ctx, v any) render.Render {
+		return render.JSON{Data: v}
+	})
+	e.add(MIMEApplicationXML, func(_ Ctx, v any) render.Render {
+		return render.XML{Header: wool.XMLHeader, Data: v}
+	})
+	return e
+}
+
+func (e *encoders) add(mediaType string, encoder Encoder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := e.encoders[mediaType]; !ok {
+		e.mediaTypes = append(e.mediaTypes, mediaType)
+	}
+	e.encoders[mediaType] = encoder
+}
+
+// negotiate returns the encoder of the media type preferred by r, or the
+// registered media types when none is acceptable.
+func (e *encoders) negotiate(r *Request) (Encoder, []string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if mediaType := r.AcceptsTypes(e.mediaTypes...); mediaType != "" {
+		return e.encoders[mediaType], nil
+	}
+	return nil, e.mediaTypes[:len(e.mediaTypes):len(e.mediaTypes)]
+}
+
+func (e *encoders) types() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.mediaTypes[:len(e.mediaTypes):len(e.mediaTypes)]
+}
+
+// encodeBuffer records the output of an encoder, an encoding error leaves the
+// response untouched.
+type encodeBuffer struct {
+	bytes.Buffer
+	header http.Header
+}
+
+func (b *encodeBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *encodeBuffer) WriteHeader(int) {}
+
+func (b *encodeBuffer) Render(w http.ResponseWriter) error {
+	b.WriteContentType(w)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func (b *encodeBuffer) WriteContentType(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+}
+
+func WithEncoder(mediaType string, encoder Encoder) Option {
+	return func(w *Wool) {
+		w.RegisterEncoder(mediaType, encoder)
+	}
+}
+
+// RegisterEncoder makes Ctx.Encode offer mediaType, encoded by encoder. Media
+// types are offered in registration order, a media type that is registered
+// again keeps its place.
+func (wool *Wool) RegisterEncoder(mediaType string, encoder Encoder) {
+	wool.encoders.add(mediaType, encoder)
+}
+
+// EncoderTypes returns the media types offered by Encode in registration
+// order.
+func (c *DefaultCtx) EncoderTypes() []string {
+	return c.wool.encoders.types()
+}
+
+// Encode renders v with the Encoder of the registered media type that is
+// preferred by the Accept header. It responds with 406 Not Acceptable and the
+// registered media types when none is acceptable. v is encoded before anything
+// is written, nothing is written when the encoding fails.
+func (c *DefaultCtx) Encode(status int, v any) error {
+	if header := c.Res().Header(); !containsToken(header.Values(HeaderVary), HeaderAccept) {
+		header.Add(HeaderVary, HeaderAccept)
+	}
+
+	encoder, mediaTypes := c.wool.encoders.negotiate(c.Req())
+	if encoder == nil {
+		return NewErrNotAcceptable(nil, mediaTypes)
+	}
+	buf := &encodeBuffer{header: http.Header{}}
+	if err := encoder(c, v).Render(buf); err != nil {
+		return err
+	}
+	return c.Render(status, buf)
+}
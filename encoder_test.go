@@ -0,0 +1,62 @@
+package wool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowool/wool/render"
+)
+
+func TestDefaultErrorHandler(t *testing.T) {
+	w := newTestWool()
+	w.RegisterEncoder("application/msgpack", func(_ Ctx, v any) render.Render {
+		return render.Blob{ContentType: "application/msgpack", Data: []byte("msgpack")}
+	})
+	w.GET("/plain", func(Ctx) error {
+		return NewErrBadRequest(nil)
+	})
+	w.GET("/map", func(Ctx) error {
+		e := NewErrBadRequest(nil)
+		e.Data = Map{"field": "name"}
+		return e
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"no accept", "/plain", "", "text/plain", "code=400"},
+		{"any", "/plain", "*/*", "text/plain", "code=400"},
+		{"json", "/plain", "application/json", "application/json", `"code":400`},
+		{"xml", "/plain", "application/xml", "application/xml", "<code>400</code>"},
+		{"msgpack over text", "/plain", "application/msgpack, text/plain;q=0.5", "application/msgpack", "msgpack"},
+		{"text over msgpack", "/plain", "application/msgpack;q=0.5, text/plain", "text/plain", "code=400"},
+		{"unacceptable", "/plain", "image/png", "text/plain", "code=400"},
+		{"map as json", "/map", "application/json", "application/json", `"field":"name"`},
+		{"map as xml", "/map", "application/xml", "text/plain", "data=map[field:name]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set(HeaderAccept, tt.accept)
+			}
+			rec := serveRequest(w, r)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if got := rec.Header().Get(HeaderContentType); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Body.String(); !strings.Contains(got, tt.body) {
+				t.Errorf("body = %q, want it to contain %q", got, tt.body)
+			}
+		})
+	}
+}
@@ -6,11 +6,11 @@ import (
 )
 
 type Error struct {
-	Code      int    `json:"code,omitempty"`
-	Message   string `json:"message,omitempty"`
-	Data      any    `json:"data,omitempty"`
-	Developer string `json:"developer_message,omitempty"`
-	Internal  error  `json:"-"`
+	Code      int    `json:"code,omitempty" xml:"code,omitempty"`
+	Message   string `json:"message,omitempty" xml:"message,omitempty"`
+	Data      any    `json:"data,omitempty" xml:"data,omitempty"`
+	Developer string `json:"developer_message,omitempty" xml:"developer_message,omitempty"`
+	Internal  error  `json:"-" xml:"-"`
 }
 
 func (e *Error) Error() string {
@@ -52,6 +52,13 @@ func NewErrMethodNotAllowed(err error, message ...string) *Error {
 	return NewError(http.StatusMethodNotAllowed, err, message...)
 }
 
+func NewErrNotAcceptable(err error, data any, message ...string) *Error {
+	e := NewError(http.StatusNotAcceptable, err, message...)
+	e.Data = data
+
+	return e
+}
+
 func NewErrConflict(err error, message ...string) *Error {
 	return NewError(http.StatusConflict, err, message...)
 }
@@ -60,6 +67,10 @@ func NewErrRequestEntityTooLarge(err error, message ...string) *Error {
 	return NewError(http.StatusRequestEntityTooLarge, err, message...)
 }
 
+func NewErrUnsupportedMediaType(err error, message ...string) *Error {
+	return NewError(http.StatusUnsupportedMediaType, err, message...)
+}
+
 func NewErrUnprocessableEntity(err error, data any, message ...string) *Error {
 	e := NewError(http.StatusUnprocessableEntity, err, message...)
 	e.Data = data
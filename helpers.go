@@ -1,5 +1,10 @@
 package wool
 
+import (
+	"reflect"
+	"runtime"
+)
+
 // MIME types that are commonly used
 const (
 	MIMETextXML         = "text/xml"
@@ -128,10 +133,12 @@ const (
 	HeaderSecWebSocketProtocol            = "Sec-WebSocket-Protocol"
 	HeaderSecWebSocketVersion             = "Sec-WebSocket-Version"
 	HeaderAcceptPatch                     = "Accept-Patch"
+	HeaderAcceptPost                      = "Accept-Post"
 	HeaderAcceptPushPolicy                = "Accept-Push-Policy"
 	HeaderAcceptSignature                 = "Accept-Signature"
 	HeaderAltSvc                          = "Alt-Svc"
 	HeaderDate                            = "Date"
+	HeaderDeprecation                     = "Deprecation"
 	HeaderIndex                           = "Index"
 	HeaderLargeAllocation                 = "Large-Allocation"
 	HeaderLink                            = "Link"
@@ -141,6 +148,7 @@ const (
 	HeaderSignature                       = "Signature"
 	HeaderSignedHeaders                   = "Signed-Headers"
 	HeaderSourceMap                       = "SourceMap"
+	HeaderSunset                          = "Sunset"
 	HeaderUpgrade                         = "Upgrade"
 	HeaderXDNSPrefetchControl             = "X-DNS-Prefetch-Control"
 	HeaderXPingback                       = "X-Pingback"
@@ -160,3 +168,10 @@ func contains(s []string, e string) bool {
 	}
 	return false
 }
+
+func funcName(fn any) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package wool
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+type host struct {
+	pattern string
+	labels  []string
+	tree    *tree
+}
+
+// Host creates a sub-router whose routes only match requests for the host
+// pattern, e.g. "api.example.com" or "{tenant}.example.com". A "{param}" label
+// matches exactly one non-empty label of the host and is available with
+// Request.HostParam. Hosts without params are matched first, then the
+// patterns with params in registration order. Requests for any other host are
+// served by the routes that were not registered through Host. Calling Host
+// again with the same pattern adds to the same routes.
+func (wool *Wool) Host(pattern string, fn ...func(*Wool)) *Wool {
+	h := *wool
+	h.host = lowerHostPattern(pattern)
+	h.middlewares = append([]Middleware(nil), wool.middlewares...)
+	h.tree = wool.router.addHost(h.host, &h)
+
+	for _, f := range fn {
+		f(&h)
+	}
+	return &h
+}
+
+// lowerHostPattern lowercases the labels of pattern and keeps the names of its
+// params as written.
+func lowerHostPattern(pattern string) string {
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		if !isHostParam(label) {
+			labels[i] = strings.ToLower(label)
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+func isHostParam(label string) bool {
+	return strings.HasPrefix(label, "{") && strings.HasSuffix(label, "}")
+}
+
+func (r *router) addHost(pattern string, owner *Wool) *tree {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, ok := r.exact[pattern]; ok {
+		return h.tree
+	}
+	for _, h := range r.hosts {
+		if h.pattern == pattern {
+			return h.tree
+		}
+	}
+
+	h := &host{pattern: pattern, labels: strings.Split(pattern, "."), tree: newTree(owner)}
+	if strings.Contains(pattern, "{") {
+		r.hosts = append(r.hosts, h)
+	} else {
+		r.exact[pattern] = h
+	}
+	return h.tree
+}
+
+// matchHost returns the tree of the host scope serving hostname and the params
+// captured from it.
+func (r *router) matchHost(hostname string) (*tree, PathParams) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.exact) == 0 && len(r.hosts) == 0 {
+		return r.tree, nil
+	}
+
+	if h, _, err := net.SplitHostPort(hostname); err == nil {
+		hostname = h
+	}
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+
+	if h, ok := r.exact[hostname]; ok {
+		return h.tree, nil
+	}
+	for _, h := range r.hosts {
+		if params, ok := h.match(hostname); ok {
+			return h.tree, params
+		}
+	}
+	return r.tree, nil
+}
+
+func (h *host) match(hostname string) (PathParams, bool) {
+	params := PathParams{}
+
+	for i, label := range h.labels {
+		part := hostname
+		if i < len(h.labels)-1 {
+			j := strings.IndexByte(hostname, '.')
+			if j < 0 {
+				return nil, false
+			}
+			part, hostname = hostname[:j], hostname[j+1:]
+		}
+
+		if isHostParam(label) {
+			if part == "" || strings.IndexByte(part, '.') >= 0 {
+				return nil, false
+			}
+			params[label[1:len(label)-1]] = []string{part}
+		} else if label != part {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func HostParamsFromContext(ctx context.Context) PathParams {
+	if p, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok && p.host != nil {
+		return p.host
+	}
+	return PathParams{}
+}
+
+func ContextWithHostParams(ctx context.Context, params PathParams) context.Context {
+	p := &routeParams{host: params}
+	if prev, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok {
+		p.params = prev.params
+		p.values = prev.values
+	}
+	return context.WithValue(ctx, ctxPathParamsKey{}, p)
+}
@@ -0,0 +1,52 @@
+package wool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHost(t *testing.T) {
+	hostEcho := func(name string) Handler {
+		return func(c Ctx) error {
+			return c.String(http.StatusOK, "%s %s", name, c.Req().HostParam("Tenant"))
+		}
+	}
+
+	w := newTestWool()
+	w.GET("/", hostEcho("fallback"))
+	w.Host("API.example.com").GET("/", hostEcho("api"))
+	w.Host("{Tenant}.example.com").GET("/", hostEcho("tenant"))
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"api.example.com", "api "},
+		{"API.Example.com:8080", "api "},
+		{"api.example.com.", "api "},
+		{"acme.example.com", "tenant acme"},
+		{"Acme.EXAMPLE.com:443", "tenant acme"},
+		{"example.com", "fallback "},
+		{"a.b.example.com", "fallback "},
+		{"other.org", "fallback "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tt.host
+			rec := serveRequest(w, r)
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	hosts := map[string]bool{}
+	for _, info := range w.Routes() {
+		hosts[info.Host] = true
+	}
+	if !hosts["api.example.com"] || !hosts["{Tenant}.example.com"] {
+		t.Errorf("route hosts = %v", hosts)
+	}
+}
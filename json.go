@@ -0,0 +1,174 @@
+package wool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/goccy/go-json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONBinding configures how BindJSON decodes request bodies.
+// DisallowUnknownFields rejects object keys that have no matching field,
+// UseNumber decodes numbers into an any as json.Number, MaxBytes limits the
+// size of the body, answering larger bodies with 413 Request Entity Too Large,
+// and DisallowTrailingData rejects anything but whitespace after the value.
+//
+// Decoding errors are answered with 400 Bad Request, the Data of the Error
+// holds the "offset" in the body and the JSON key of the offending "field"
+// when it is known.
+type JSONBinding struct {
+	DisallowUnknownFields bool
+	UseNumber             bool
+	MaxBytes              int64
+	DisallowTrailingData  bool
+}
+
+type ctxJSONBindingKey struct{}
+
+func WithJSONBinding(cfg JSONBinding) Option {
+	return func(w *Wool) {
+		w.JSONBinding = cfg
+	}
+}
+
+// JSONBindingMiddleware makes BindJSON use cfg instead of the JSONBinding of
+// the Wool, for a single route or a group.
+func JSONBindingMiddleware(cfg JSONBinding) Middleware {
+	return func(next Handler) Handler {
+		return func(c Ctx) error {
+			c.SetReq(c.Req().WithContext(ContextWithJSONBinding(c.Req().Context(), cfg)))
+			return next(c)
+		}
+	}
+}
+
+func JSONBindingFromContext(ctx context.Context) (JSONBinding, bool) {
+	cfg, ok := ctx.Value(ctxJSONBindingKey{}).(JSONBinding)
+	return cfg, ok
+}
+
+func ContextWithJSONBinding(ctx context.Context, cfg JSONBinding) context.Context {
+	return context.WithValue(ctx, ctxJSONBindingKey{}, cfg)
+}
+
+func (cfg JSONBinding) decode(c Ctx, i any) error {
+	body := &readErrRecorder{Reader: c.Req().Body}
+	if cfg.MaxBytes > 0 {
+		body.Reader = http.MaxBytesReader(c.Res(), c.Req().Body, cfg.MaxBytes)
+	}
+
+	dec := json.NewDecoder(body)
+	if cfg.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if cfg.UseNumber {
+		dec.UseNumber()
+	}
+
+	if err := dec.Decode(i); err != nil {
+		return jsonError(err, body.err, dec.InputOffset(), i)
+	}
+
+	if cfg.DisallowTrailingData {
+		if _, err := dec.Token(); err != io.EOF {
+			if err == nil {
+				err = errors.New("json: trailing data after the value")
+			}
+			return jsonError(err, body.err, dec.InputOffset(), i)
+		}
+	}
+	return nil
+}
+
+// readErrRecorder keeps the error of the body, go-json reports a body that
+// could not be read completely as a syntax error.
+type readErrRecorder struct {
+	io.Reader
+	err error
+}
+
+func (r *readErrRecorder) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return
+}
+
+func jsonError(err, readErr error, offset int64, i any) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(readErr, &maxBytesErr) {
+		return NewErrRequestEntityTooLarge(readErr)
+	}
+
+	data := Map{"offset": offset}
+
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &typeErr):
+		data["offset"] = typeErr.Offset
+		if typeErr.Field != "" {
+			data["field"] = jsonKey(reflect.TypeOf(i), typeErr.Struct, typeErr.Field)
+		}
+	case errors.As(err, &syntaxErr):
+		data["offset"] = syntaxErr.Offset
+	default:
+		// go-json reports unknown fields without a dedicated error type
+		if key, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if field, unquoteErr := strconv.Unquote(key); unquoteErr == nil {
+				data["field"] = field
+			}
+		}
+	}
+
+	e := NewErrBadRequest(fmt.Errorf("invalid JSON body: %w", err))
+	e.Data = data
+	return e
+}
+
+// jsonKey returns the JSON key of the field of the struct type named
+// structName found in typ. go-json reports the Go names of the struct and the
+// field, the client knows the key. The field name is returned when the struct
+// cannot be found.
+func jsonKey(typ reflect.Type, structName, field string) string {
+	seen := map[reflect.Type]bool{}
+	var find func(t reflect.Type) (reflect.StructField, bool)
+	find = func(t reflect.Type) (reflect.StructField, bool) {
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct || seen[t] {
+			return reflect.StructField{}, false
+		}
+		seen[t] = true
+
+		if t.Name() == structName {
+			if sf, ok := t.FieldByName(field); ok {
+				return sf, true
+			}
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if sf, ok := find(t.Field(i).Type); ok {
+				return sf, true
+			}
+		}
+		return reflect.StructField{}, false
+	}
+
+	sf, ok := find(typ)
+	if !ok {
+		return field
+	}
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field
+}
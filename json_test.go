@@ -0,0 +1,66 @@
+package wool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBindJSON(t *testing.T) {
+	type user struct {
+		Age int `json:"age"`
+	}
+	type payload struct {
+		A     int    `json:"a,omitempty"`
+		User  user   `json:"user"`
+		Items []user `json:"items"`
+	}
+	bindJSON := func(c Ctx) error {
+		var p payload
+		if err := c.BindJSON(&p); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "a=%d", p.A)
+	}
+
+	w := newTestWool()
+	w.JSONBinding = JSONBinding{MaxBytes: 32, DisallowTrailingData: true}
+	w.POST("/", bindJSON)
+	w.POST("/strict", bindJSON, JSONBindingMiddleware(JSONBinding{DisallowUnknownFields: true}))
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		code int
+		want string
+	}{
+		{"ok", "/", `{"a":1}`, http.StatusOK, "a=1"},
+		{"trailing whitespace", "/", "{\"a\":1} \n", http.StatusOK, "a=1"},
+		{"trailing data", "/", `{"a":1} x`, http.StatusBadRequest, `"offset":`},
+		{"second value", "/", `{"a":1}{"a":2}`, http.StatusBadRequest, `"offset":`},
+		{"type field", "/", `{"a":"x"}`, http.StatusBadRequest, `"field":"a"`},
+		{"nested type field", "/", `{"user":{"age":"x"}}`, http.StatusBadRequest, `"field":"age"`},
+		{"slice type field", "/", `{"items":[{"age":true}]}`, http.StatusBadRequest, `"field":"age"`},
+		{"too large", "/", `{"a":1,"user":{"age":1},"items":[]}`, http.StatusRequestEntityTooLarge, ""},
+		{"unknown field allowed", "/", `{"b":1}`, http.StatusOK, "a=0"},
+		{"unknown field", "/strict", `{"b":1}`, http.StatusBadRequest, `"field":"b"`},
+		{"route config replaces app config", "/strict", `{"a":1,"user":{"age":1},"items":[]} x`, http.StatusOK, "a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			r.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			r.Header.Set(HeaderAccept, MIMEApplicationJSON)
+			rec := serveRequest(w, r)
+
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.code, rec.Body)
+			}
+			if got := rec.Body.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("body = %s, want it to contain %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package wool
 
-import "net/http"
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+)
 
 const patternID = "/:id"
 
@@ -25,40 +31,40 @@ type (
 	}
 )
 
-func (wool *Wool) GET(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodGet, http.MethodHead)
+func (wool *Wool) GET(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodGet, http.MethodHead}, mw...)
 }
 
-func (wool *Wool) HEAD(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodHead)
+func (wool *Wool) HEAD(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodHead}, mw...)
 }
 
-func (wool *Wool) POST(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodPost)
+func (wool *Wool) POST(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodPost}, mw...)
 }
 
-func (wool *Wool) PUT(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodPut)
+func (wool *Wool) PUT(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodPut}, mw...)
 }
 
-func (wool *Wool) PATCH(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodPatch)
+func (wool *Wool) PATCH(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodPatch}, mw...)
 }
 
-func (wool *Wool) DELETE(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodDelete)
+func (wool *Wool) DELETE(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodDelete}, mw...)
 }
 
-func (wool *Wool) CONNECT(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodConnect)
+func (wool *Wool) CONNECT(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodConnect}, mw...)
 }
 
-func (wool *Wool) OPTIONS(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodOptions)
+func (wool *Wool) OPTIONS(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodOptions}, mw...)
 }
 
-func (wool *Wool) TRACE(pattern string, handler Handler) {
-	wool.Add(pattern, handler, http.MethodTrace)
+func (wool *Wool) TRACE(pattern string, handler Handler, mw ...Middleware) *Route {
+	return wool.add(pattern, handler, []string{http.MethodTrace}, mw...)
 }
 
 func (wool *Wool) CRUD(pattern string, resource any, mw ...Middleware) {
@@ -96,3 +102,30 @@ func (wool *Wool) MountHealth() {
 		return c.NoContent()
 	})
 }
+
+// RoutesHandler renders the route table as JSON or as plain text, depending
+// on the Accept header.
+func (wool *Wool) RoutesHandler() Handler {
+	return func(c Ctx) error {
+		routes := wool.Routes()
+
+		if c.NegotiateFormat(MIMETextPlain, MIMEApplicationJSON) == MIMEApplicationJSON {
+			return c.JSON(http.StatusOK, routes)
+		}
+
+		var buf bytes.Buffer
+		tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "METHOD\tHOST\tPATTERN\tVERSION\tNAME\tHANDLER\tMIDDLEWARES")
+		for _, route := range routes {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Host, route.Pattern, route.Version, route.Name, route.Handler, strings.Join(route.Middlewares, ","))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, buf.String())
+	}
+}
+
+func (wool *Wool) MountRoutes(pattern string, mw ...Middleware) {
+	wool.GET(pattern, wool.RoutesHandler(), mw...)
+}
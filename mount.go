@@ -0,0 +1,76 @@
+package wool
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ctxOriginalPathKey struct{}
+
+// OriginalPathFromContext returns the request path as it was before a Mount
+// stripped its prefix or an empty string when the request was not mounted.
+func OriginalPathFromContext(ctx context.Context) string {
+	if p, ok := ctx.Value(ctxOriginalPathKey{}).(string); ok {
+		return p
+	}
+	return ""
+}
+
+// Mount serves handler for every method on prefix and every path below it.
+// The prefix, which may contain params, is stripped from the path that
+// handler sees; the original path is kept in the request context.
+func (wool *Wool) Mount(prefix string, handler http.Handler, mw ...Middleware) {
+	h := func(c Ctx) error {
+		handler.ServeHTTP(c.Res(), stripPrefix(c.Req().Request, c.Req().PathParam("...")))
+		return nil
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	wool.add(prefix, h, nil, mw...)
+	wool.add(prefix+"/...", h, nil, mw...)
+}
+
+// MountApp mounts app under prefix. The app serves the requests with its own
+// middlewares, error handling and not found handlers.
+func (wool *Wool) MountApp(prefix string, app *Wool, mw ...Middleware) {
+	wool.Mount(prefix, app, mw...)
+}
+
+// mountPrefix returns the prefix a Mount stripped from the path of r.
+func mountPrefix(r *http.Request) string {
+	original := OriginalPathFromContext(r.Context())
+	if original == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(original, r.URL.Path), "/")
+}
+
+func stripPrefix(r *http.Request, rest string) *http.Request {
+	ctx := r.Context()
+	if OriginalPathFromContext(ctx) == "" {
+		ctx = context.WithValue(ctx, ctxOriginalPathKey{}, r.URL.Path)
+	}
+
+	r2 := r.WithContext(ctx)
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/" + rest
+	r2.URL.RawPath = ""
+
+	if raw := r.URL.RawPath; raw != "" {
+		for i := 0; i < len(raw); i++ {
+			if raw[i] != '/' {
+				continue
+			}
+			if unescaped, err := url.PathUnescape(raw[i+1:]); err == nil && unescaped == rest {
+				r2.URL.RawPath = raw[i:]
+				break
+			}
+		}
+	}
+
+	return r2
+}
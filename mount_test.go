@@ -0,0 +1,66 @@
+package wool
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMountApp(t *testing.T) {
+	app := newTestWool()
+	app.GET("/", echo("index"))
+	app.GET("/users/:id", echo("user"))
+
+	w := newTestWool()
+	w.MountApp("/sub", app)
+	w.MountApp("/tenants/:tenant", app)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/sub/", "index"},
+		{"/sub/users/7", "user id=7"},
+		{"/tenants/acme/", "index"},
+		{"/tenants/acme/users/7", "user id=7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(w, http.MethodGet, tt.path)
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMountAppRedirect(t *testing.T) {
+	app := newTestWool()
+	app.Redirect = RedirectConfig{CleanPath: true, TrailingSlash: true}
+	app.GET("/x", echo("x"))
+	app.GET("/y/", echo("y"))
+
+	w := newTestWool()
+	w.MountApp("/admin", app)
+	w.MountApp("/tenants/:tenant", app)
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/admin/x/", "/admin/x"},
+		{"/admin/y", "/admin/y/"},
+		{"/admin/a/../x", "/admin/x"},
+		{"/tenants/acme/x/?q=1", "/tenants/acme/x?q=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(w, http.MethodGet, tt.path)
+			if rec.Code != http.StatusMovedPermanently {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+			}
+			if got := rec.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package wool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	readCloserType  = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+)
+
+func isFileType(typ reflect.Type) bool {
+	return typ == fileHeaderType || typ == fileHeadersType || typ == readCloserType
+}
+
+// bindFile binds the uploaded files of n to a field of type
+// *multipart.FileHeader, []*multipart.FileHeader or io.ReadCloser. The file of
+// an io.ReadCloser is opened and has to be closed by the handler, unless Bind
+// fails.
+//
+// The tags of the field can limit the files: `maxsize:"2MB"` answers larger
+// files with 413 Request Entity Too Large and `accept:"image/png,image/*"`
+// answers files whose declared Content-Type does not match with 415
+// Unsupported Media Type.
+func bindFile(field reflect.Value, n *bindNode, typeField reflect.StructField) (bool, error) {
+	files := n.files
+	if appended, ok := n.children[""]; ok {
+		files = append(files[:len(files):len(files)], appended.files...)
+	}
+	if len(files) == 0 {
+		return false, nil
+	}
+
+	for _, file := range files {
+		if err := checkFile(file, typeField); err != nil {
+			return false, err
+		}
+	}
+
+	switch typeField.Type {
+	case fileHeadersType:
+		field.Set(reflect.ValueOf(files))
+	case fileHeaderType:
+		field.Set(reflect.ValueOf(files[0]))
+	default:
+		f, err := files[0].Open()
+		if err != nil {
+			return false, err
+		}
+		n.state.opened = append(n.state.opened, f)
+		field.Set(reflect.ValueOf(f))
+	}
+	return true, nil
+}
+
+// closeFiles closes the files opened for io.ReadCloser fields when Bind fails,
+// the handler never sees them.
+func (s *bindState) closeFiles() {
+	for _, f := range s.opened {
+		_ = f.Close()
+	}
+	s.opened = nil
+}
+
+func checkFile(file *multipart.FileHeader, typeField reflect.StructField) error {
+	if value, ok := typeField.Tag.Lookup("maxsize"); ok {
+		maxSize, err := parseSize(value)
+		if err != nil {
+			return fmt.Errorf("maxsize of %s: %w", typeField.Name, err)
+		}
+		if file.Size > maxSize {
+			return NewErrRequestEntityTooLarge(fmt.Errorf("file %q of %s is larger than %s", file.Filename, typeField.Name, value))
+		}
+	}
+
+	if value, ok := typeField.Tag.Lookup("accept"); ok {
+		mediaType, _, _ := mime.ParseMediaType(file.Header.Get(HeaderContentType))
+		if !acceptsMediaType(value, mediaType) {
+			return NewErrUnsupportedMediaType(fmt.Errorf("file %q of %s has the unsupported media type %q", file.Filename, typeField.Name, mediaType))
+		}
+	}
+	return nil
+}
+
+// acceptsMediaType reports whether mediaType matches one of the comma
+// separated media ranges such as "image/png", "image/*" or "*/*".
+func acceptsMediaType(ranges, mediaType string) bool {
+	if mediaType == "" {
+		return false
+	}
+	typ, _, _ := strings.Cut(mediaType, "/")
+	for _, r := range strings.Split(ranges, ",") {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "*/*" || r == mediaType || (strings.HasSuffix(r, "/*") && r[:len(r)-2] == typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSize parses a number of bytes with an optional unit such as "512KB",
+// "2MB" or "1GB", the units are powers of 1024.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(s[:len(s)-len(u.suffix)]), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * unit, nil
+}
+
+// PartHandler handles a part of a streamed multipart body. The part does not
+// have to be read completely, the rest is discarded.
+type PartHandler func(p *Part) error
+
+// MultipartStream configures Ctx.StreamMultipart. The parts are passed to the
+// handler registered for their form name in Handlers, or to Default; parts
+// without handler are discarded. MaxParts, MaxPartSize and MaxTotalSize limit
+// the number of parts, the size of a single part and the size of the whole
+// body, a limit of 0 is no limit. Exceeding a limit is answered with 413
+// Request Entity Too Large.
+type MultipartStream struct {
+	Handlers     map[string]PartHandler
+	Default      PartHandler
+	MaxParts     int
+	MaxPartSize  int64
+	MaxTotalSize int64
+}
+
+// Part is a part of a streamed multipart body, reading it enforces the limits
+// of the MultipartStream and stops when the request context is done.
+type Part struct {
+	*multipart.Part
+	ctx     context.Context
+	read    int64
+	maxSize int64
+}
+
+func (p *Part) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if p.maxSize > 0 && p.read > p.maxSize {
+		return 0, p.tooLarge()
+	}
+
+	n, err := p.Part.Read(b)
+	p.read += int64(n)
+	if p.maxSize > 0 && p.read > p.maxSize {
+		if n -= int(p.read - p.maxSize); n < 0 {
+			n = 0
+		}
+		return n, p.tooLarge()
+	}
+	return n, err
+}
+
+func (p *Part) tooLarge() error {
+	return NewErrRequestEntityTooLarge(fmt.Errorf("part %q is larger than %d bytes", p.FormName(), p.maxSize))
+}
+
+// StreamMultipart reads a multipart body part by part as it arrives, without
+// storing it in memory or temporary files like BindForm does. It stops at the
+// first error of a handler and returns it, and when the request context is
+// done.
+func (c *DefaultCtx) StreamMultipart(s MultipartStream) error {
+	req := c.Req()
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get(HeaderContentType)); !strings.HasPrefix(mediaType, "multipart/") {
+		return NewErrUnsupportedMediaType(fmt.Errorf("unsupported media type %q", mediaType))
+	}
+
+	if s.MaxTotalSize > 0 {
+		req.Body = http.MaxBytesReader(c.Res(), req.Body, s.MaxTotalSize)
+	}
+
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return NewErrBadRequest(err)
+	}
+
+	ctx := req.Context()
+	for count := 0; ; count++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return multipartError(err)
+		}
+
+		if s.MaxParts > 0 && count >= s.MaxParts {
+			_ = part.Close()
+			return NewErrRequestEntityTooLarge(fmt.Errorf("multipart body has more than %d parts", s.MaxParts))
+		}
+
+		p := &Part{Part: part, ctx: ctx, maxSize: s.MaxPartSize}
+		handler, ok := s.Handlers[part.FormName()]
+		if !ok {
+			handler = s.Default
+		}
+		if handler != nil {
+			if err = handler(p); err != nil {
+				_ = part.Close()
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return NewErrRequestEntityTooLarge(err)
+				}
+				return err
+			}
+		}
+
+		// the rest of the part counts against the limits as well
+		if _, err = io.Copy(io.Discard, p); err != nil {
+			return multipartError(err)
+		}
+		_ = part.Close()
+	}
+}
+
+// multipartError turns an error reading the body into a 413 when it is too
+// large and into a 400 when it is malformed.
+func multipartError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return NewErrRequestEntityTooLarge(err)
+	}
+
+	var e *Error
+	if errors.As(err, &e) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return NewErrBadRequest(err)
+}
@@ -0,0 +1,105 @@
+package wool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"512", 512, true},
+		{"2KB", 2 << 10, true},
+		{" 3 mb ", 3 << 20, true},
+		{"1GB", 1 << 30, true},
+		{"8589934591GB", 8589934591 << 30, true},
+		{"8589934592GB", 0, false},
+		{"9223372036854775807", 9223372036854775807, true},
+		{"9223372036854775807KB", 0, false},
+		{"-1", 0, false},
+		{"MB", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseSize(tt.in)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %t", err, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindFileClosedOnError(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	_, _ = fw.Write([]byte("content"))
+	_ = mw.WriteField("count", "x")
+	_ = mw.Close()
+
+	// a file larger than the memory limit is stored on disk and opened as an
+	// *os.File, which reports whether it has been closed
+	form, err := multipart.NewReader(&body, mw.Boundary()).ReadForm(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = form.RemoveAll() }()
+
+	var dest struct {
+		File  io.ReadCloser `form:"file"`
+		Count int           `form:"count"`
+	}
+	if err = bind(&dest, form.Value, form.File, "form", BindLimits{}); err == nil {
+		t.Fatal("bind succeeded, want an error for count")
+	}
+
+	f, ok := dest.File.(*os.File)
+	if !ok {
+		t.Fatalf("file is a %T, want an *os.File", dest.File)
+	}
+	if _, err = f.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("read = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestPartReadBeyondLimit(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("data", "0123456789abcdef")
+	_ = mw.Close()
+
+	part, err := multipart.NewReader(&body, mw.Boundary()).NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Part{Part: part, ctx: context.Background(), maxSize: 4}
+
+	var e *Error
+	for i, size := range []int{8, 8, 1} {
+		n, err := p.Read(make([]byte, size))
+		if n < 0 || n > 4 {
+			t.Errorf("read %d: n = %d, want 0 to 4", i, n)
+		}
+		if !errors.As(err, &e) || e.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("read %d: err = %v, want 413", i, err)
+		}
+	}
+
+	// bufio.Reader panics on a negative count
+	if _, err = bufio.NewReader(p).ReadString('\n'); !errors.As(err, &e) {
+		t.Errorf("err = %v, want 413", err)
+	}
+}
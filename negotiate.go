@@ -0,0 +1,169 @@
+package wool
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a single element of an Accept, Accept-Language,
+// Accept-Charset or Accept-Encoding header.
+type acceptRange struct {
+	value  string
+	params []string
+	q      float64
+}
+
+// parseAccept parses the comma separated ranges of header together with their
+// q-values. Ranges with an invalid q-value are dropped. The result is sorted by
+// q-value, keeping the header order of ranges with the same q-value.
+func parseAccept(header string) []acceptRange {
+	if header == "" {
+		return nil
+	}
+
+	parts := strings.Split(header, ",")
+	ranges := make([]acceptRange, 0, len(parts))
+
+outer:
+	for _, part := range parts {
+		fields := strings.Split(part, ";")
+		r := acceptRange{value: strings.ToLower(strings.TrimSpace(fields[0])), q: 1}
+		if r.value == "" {
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if key == "q" {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					continue outer
+				}
+				r.q = q
+				// accept-ext params after the q-value are not media type params
+				break
+			}
+			if key != "" {
+				r.params = append(r.params, key+"="+strings.ToLower(value))
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	return ranges
+}
+
+// negotiate returns the offer with the highest q-value. The q-value of an
+// offer is taken from the most specific range matching it, match returns the
+// specificity of a match or -1. Ties are broken by specificity and then by the
+// order of the offers. All offers are acceptable without ranges. An offer no
+// range matches gets the q-value implicit, which is 0 for most headers.
+func negotiate(ranges []acceptRange, offers []string, match func(r *acceptRange, offer string) int, implicit func(offer string) float64) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ, bestSpecificity := "", 0.0, -1
+
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for i := range ranges {
+			if s := match(&ranges[i], offer); s > specificity {
+				q, specificity = ranges[i].q, s
+			}
+		}
+		if specificity < 0 && implicit != nil {
+			q = implicit(offer)
+		}
+		if q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+
+	return best
+}
+
+// matchMediaType matches offers such as "text/html" or
+// "application/json; version=2" against "*/*", "type/*", "type/subtype" and
+// "type/subtype" with params, in order of increasing specificity. The params
+// of a range all have to be present in the offer.
+func matchMediaType(r *acceptRange, offer string) int {
+	mediaType, params, _ := strings.Cut(offer, ";")
+	offerType, offerSubtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+	rangeType, rangeSubtype, _ := strings.Cut(r.value, "/")
+
+	switch {
+	case rangeType == "*" && rangeSubtype == "*":
+		return 0
+	case rangeType != offerType && offerType != "*":
+		return -1
+	case rangeSubtype == "*":
+		return 1
+	case rangeSubtype != offerSubtype && offerSubtype != "*":
+		return -1
+	}
+
+	if len(r.params) == 0 {
+		return 2
+	}
+
+	offerParams := map[string]bool{}
+	for _, param := range strings.Split(params, ";") {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			offerParams[strings.ToLower(strings.TrimSpace(key))+"="+strings.ToLower(strings.Trim(strings.TrimSpace(value), `"`))] = true
+		}
+	}
+	for _, param := range r.params {
+		if !offerParams[param] {
+			return -1
+		}
+	}
+	return 2 + len(r.params)
+}
+
+// matchLanguage implements the basic filtering of RFC 4647: "en" matches "en"
+// and "en-US", "*" matches everything.
+func matchLanguage(r *acceptRange, offer string) int {
+	if r.value == "*" {
+		return 0
+	}
+	offer = strings.ToLower(offer)
+	if offer == r.value || (strings.HasPrefix(offer, r.value) && offer[len(r.value)] == '-') {
+		return 1 + strings.Count(r.value, "-")
+	}
+	return -1
+}
+
+// matchToken matches charsets and content codings case-insensitively.
+func matchToken(r *acceptRange, offer string) int {
+	if r.value == "*" {
+		return 0
+	}
+	if strings.EqualFold(r.value, offer) {
+		return 1
+	}
+	return -1
+}
+
+// implicitIdentity makes the identity coding acceptable unless it is excluded
+// explicitly, see RFC 9110 12.5.3.
+func implicitIdentity(offer string) float64 {
+	if strings.EqualFold(offer, "identity") {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package wool
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidUUID = errors.New("invalid UUID")
+
+var rxSlug = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// DefaultParamTypes are the param types every Wool starts with, they are used
+// in patterns as ":id<int>", ":uid<uuid>", ":day<date>" and ":name<slug>".
+var DefaultParamTypes = map[string]ParamType{
+	"int": {
+		Parse: func(value string) (any, error) {
+			return strconv.Atoi(value)
+		},
+	},
+	"uuid": {
+		Match: func(value string) bool {
+			_, err := ParseUUID(value)
+			return err == nil
+		},
+		Parse: func(value string) (any, error) {
+			return ParseUUID(value)
+		},
+	},
+	"date": {
+		Match: func(value string) bool {
+			_, err := time.Parse(time.DateOnly, value)
+			return err == nil
+		},
+		Parse: func(value string) (any, error) {
+			return time.Parse(time.DateOnly, value)
+		},
+	},
+	"slug": {
+		Match: rxSlug.MatchString,
+		Parse: func(value string) (any, error) {
+			return value, nil
+		},
+	},
+}
+
+// ParamType is a named constraint of a path param. Match reports whether a
+// path segment is a valid value, a successful Parse is used when it is nil.
+// Parse converts the value which is then available with
+// Request.PathParamValue.
+type ParamType struct {
+	Match func(value string) bool
+	Parse func(value string) (any, error)
+}
+
+func (t *ParamType) match(value string) bool {
+	if t.Match != nil {
+		return t.Match(value)
+	}
+	_, err := t.Parse(value)
+	return err == nil
+}
+
+type PathValues map[string]any
+
+func PathValuesFromContext(ctx context.Context) PathValues {
+	if p, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok && p.values != nil {
+		return p.values
+	}
+	return PathValues{}
+}
+
+func WithParamType(name string, typ ParamType) Option {
+	return func(w *Wool) {
+		w.RegisterParamType(name, typ)
+	}
+}
+
+// RegisterParamType makes typ available as ":param<name>" to the patterns
+// registered afterwards.
+func (wool *Wool) RegisterParamType(name string, typ ParamType) {
+	if typ.Parse == nil {
+		panic("wool: param type without Parse func")
+	}
+	wool.router.addType(name, typ)
+}
+
+type UUID [16]byte
+
+// ParseUUID parses the canonical xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx form.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, ErrInvalidUUID
+	}
+	j := 0
+	for i := 0; i < len(s); i += 2 {
+		if s[i] == '-' {
+			i++
+		}
+		hi, ok1 := fromHexChar(s[i])
+		lo, ok2 := fromHexChar(s[i+1])
+		if !ok1 || !ok2 {
+			return u, ErrInvalidUUID
+		}
+		u[j] = hi<<4 | lo
+		j++
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) (err error) {
+	*u, err = ParseUUID(string(text))
+	return
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
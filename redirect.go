@@ -0,0 +1,93 @@
+package wool
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RedirectConfig configures the redirects to the canonical path of a request.
+// CleanPath redirects paths such as "//users/../users" to their path.Clean
+// form before routing, keeping a trailing slash. TrailingSlash and
+// CaseInsensitive only apply when no route matches the request path; they
+// redirect when a route matches after adding or removing the trailing slash,
+// or after correcting the case of static segments.
+//
+// Codes maps methods to the status code of the redirect. Without an entry GET
+// and HEAD are redirected with 301 Moved Permanently and all other methods with
+// 308 Permanent Redirect, so that their body is kept.
+type RedirectConfig struct {
+	CleanPath       bool
+	TrailingSlash   bool
+	CaseInsensitive bool
+	Codes           map[string]int
+}
+
+func (cfg RedirectConfig) code(method string) int {
+	if code, ok := cfg.Codes[method]; ok {
+		return code
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return http.StatusMovedPermanently
+	}
+	return http.StatusPermanentRedirect
+}
+
+func WithRedirect(cfg RedirectConfig) Option {
+	return func(w *Wool) {
+		w.Redirect = cfg
+	}
+}
+
+// canonicalPath returns the path the request has to be redirected to or an
+// empty string when no redirect is needed.
+func (wool *Wool) canonicalPath(t *tree, method, p string, values *[]string) string {
+	if wool.Redirect.TrailingSlash && p != "/" {
+		fixed := p + "/"
+		if strings.HasSuffix(p, "/") {
+			fixed = p[:len(p)-1]
+		}
+		*values = (*values)[:0]
+		if wool.router.find(t, method, fixed, values) != nil {
+			return fixed
+		}
+	}
+
+	if wool.Redirect.CaseInsensitive {
+		if fixed, ok := wool.router.findFold(t, method, p); ok && fixed != p {
+			return fixed
+		}
+	}
+
+	return ""
+}
+
+func (wool *Wool) redirect(c Ctx, p string) error {
+	// a leading "//" would make the location protocol-relative, the prefix
+	// stripped by a Mount is added back
+	location := (&url.URL{Path: mountPrefix(c.Req().Request) + "/" + strings.TrimLeft(p, "/")}).EscapedPath()
+	if q := c.Req().URL.RawQuery; q != "" {
+		location += "?" + q
+	}
+	code := wool.Redirect.code(c.Req().Method)
+
+	return wool.wrap(func(c Ctx) error {
+		return c.Redirect(code, location)
+	})(c)
+}
+
+// cleanPath is path.Clean that keeps the trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean(p)
+	if !strings.HasPrefix(cleaned, "/") {
+		cleaned = "/" + cleaned
+	}
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
@@ -13,13 +13,29 @@ var ErrStreamClosed = errors.New("http: Stream closed")
 
 type Map map[string]any
 
+// Offers are the representations a handler can respond with, see
+// Ctx.Negotiate. Fields left empty are not offered, HTML is the name of the
+// template that is executed with Data.
+type Offers struct {
+	JSON any
+	HTML string
+	Data any
+	XML  any
+	Text string
+}
+
 type CtxRender interface {
 	Status(status int) error
 	Render(status int, r render.Render) error
 	Blob(status int, contentType string, data []byte) error
 	JSON(status int, obj any) error
 	IndentedJSON(status int, obj any) error
+	XML(status int, obj any) error
+	IndentedXML(status int, obj any) error
 	HTML(status int, name string, obj any) error
+	Negotiate(status int, offers Offers) error
+	Encode(status int, v any) error
+	EncoderTypes() []string
 	String(status int, format string, data ...any) error
 	SSEvent(event string, data any) error
 	Stream(step func(w io.Writer) error) error
@@ -58,11 +74,54 @@ func (c *DefaultCtx) IndentedJSON(status int, obj any) error {
 	return c.Render(status, render.IndentedJSON{Data: obj})
 }
 
+func (c *DefaultCtx) XML(status int, obj any) error {
+	return c.Render(status, render.XML{Header: c.wool.XMLHeader, Data: obj})
+}
+
+func (c *DefaultCtx) IndentedXML(status int, obj any) error {
+	return c.Render(status, render.IndentedXML{Header: c.wool.XMLHeader, Data: obj})
+}
+
 func (c *DefaultCtx) HTML(status int, name string, obj any) error {
 	instance := c.wool.HTMLRender.Instance(name, obj, c.Debug())
 	return c.Render(status, instance)
 }
 
+// Negotiate renders the offer that is preferred by the Accept header, in the
+// order JSON, HTML, XML and Text when the client has no preference. It responds
+// with 406 Not Acceptable and the offered media types when none is acceptable.
+func (c *DefaultCtx) Negotiate(status int, offers Offers) error {
+	if header := c.Res().Header(); !containsToken(header.Values(HeaderVary), HeaderAccept) {
+		header.Add(HeaderVary, HeaderAccept)
+	}
+
+	offered := make([]string, 0, 4)
+	if offers.JSON != nil {
+		offered = append(offered, MIMEApplicationJSON)
+	}
+	if offers.HTML != "" && c.wool.HTMLRender != nil {
+		offered = append(offered, MIMETextHTML)
+	}
+	if offers.XML != nil {
+		offered = append(offered, MIMEApplicationXML)
+	}
+	if offers.Text != "" {
+		offered = append(offered, MIMETextPlain)
+	}
+
+	switch c.NegotiateFormat(offered...) {
+	case MIMEApplicationJSON:
+		return c.JSON(status, offers.JSON)
+	case MIMETextHTML:
+		return c.HTML(status, offers.HTML, offers.Data)
+	case MIMEApplicationXML:
+		return c.XML(status, offers.XML)
+	case MIMETextPlain:
+		return c.Render(status, render.String{Format: offers.Text})
+	}
+	return NewErrNotAcceptable(nil, offered)
+}
+
 func (c *DefaultCtx) String(status int, format string, data ...any) error {
 	return c.Render(status, render.String{Format: format, Data: data})
 }
@@ -8,6 +8,8 @@ var (
 	_ Render     = (*Blob)(nil)
 	_ Render     = (*JSON)(nil)
 	_ Render     = (*IndentedJSON)(nil)
+	_ Render     = (*XML)(nil)
+	_ Render     = (*IndentedXML)(nil)
 	_ Render     = (*String)(nil)
 	_ Render     = (*Redirect)(nil)
 	_ Render     = (*SSEvent)(nil)
@@ -22,6 +24,7 @@ const (
 	mimeTextHTMLCharsetUTF8        = "text/html; charset=utf-8"
 	mimeTextPlainCharsetUTF8       = "text/plain; charset=utf-8"
 	mimeApplicationJSONCharsetUTF8 = "application/json; charset=utf-8"
+	mimeApplicationXMLCharsetUTF8  = "application/xml; charset=utf-8"
 	mimeTextEventStreamCharsetUTF8 = "text/event-stream; charset=utf-8"
 )
 
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// XML renders Data with encoding/xml, the Header, e.g. xml.Header, is written
+// before the document when it is not empty.
+type XML struct {
+	Header string
+	Data   any
+}
+
+type IndentedXML struct {
+	Header string
+	Data   any
+}
+
+func (r XML) Render(w http.ResponseWriter) error {
+	data, err := xml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	return writeXML(w, r.Header, data)
+}
+
+func (r XML) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set(headerContentType, mimeApplicationXMLCharsetUTF8)
+}
+
+func (r IndentedXML) Render(w http.ResponseWriter) error {
+	data, err := xml.MarshalIndent(r.Data, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeXML(w, r.Header, data)
+}
+
+func (r IndentedXML) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set(headerContentType, mimeApplicationXMLCharsetUTF8)
+}
+
+func writeXML(w http.ResponseWriter, header string, data []byte) (err error) {
+	w.Header().Set(headerContentType, mimeApplicationXMLCharsetUTF8)
+	if header != "" {
+		if _, err = w.Write([]byte(header)); err != nil {
+			return
+		}
+	}
+	_, err = w.Write(data)
+	return
+}
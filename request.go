@@ -5,15 +5,18 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
-const defaultMaxMemory = 32 << 20 // 32 MB
+const DefaultMaxMultipartMemory = 32 << 20 // 32 MB
 
 type Request struct {
 	*http.Request
-	query       url.Values
-	accept      []string
-	contentType string
+	query        url.Values
+	accept       []string
+	acceptRanges []acceptRange
+	contentType  string
+	maxMemory    int64
 }
 
 func (r *Request) WithContext(ctx context.Context) *Request {
@@ -60,10 +63,43 @@ func (r *Request) Accept() []string {
 	return r.accept
 }
 
+// AcceptsTypes returns the offered media type that is preferred by the Accept
+// header according to RFC 9110, or an empty string when none is acceptable.
+func (r *Request) AcceptsTypes(offers ...string) string {
+	if r.acceptRanges == nil {
+		r.acceptRanges = parseAccept(r.Header.Get(HeaderAccept))
+	}
+	return negotiate(r.acceptRanges, offers, matchMediaType, nil)
+}
+
+// AcceptsLanguages returns the offered language tag that is preferred by the
+// Accept-Language header, "en" matches the offers "en" and "en-US".
+func (r *Request) AcceptsLanguages(offers ...string) string {
+	return negotiate(parseAccept(r.Header.Get(HeaderAcceptLanguage)), offers, matchLanguage, nil)
+}
+
+// AcceptsCharsets returns the offered charset that is preferred by the
+// Accept-Charset header.
+func (r *Request) AcceptsCharsets(offers ...string) string {
+	return negotiate(parseAccept(r.Header.Get(HeaderAcceptCharset)), offers, matchToken, nil)
+}
+
+// AcceptsEncodings returns the offered content coding that is preferred by the
+// Accept-Encoding header. The identity coding is acceptable unless it is
+// excluded with "identity;q=0" or "*;q=0".
+func (r *Request) AcceptsEncodings(offers ...string) string {
+	return negotiate(parseAccept(r.Header.Get(HeaderAcceptEncoding)), offers, matchToken, implicitIdentity)
+}
+
 func (r *Request) IsJSON() bool {
 	return r.ContentType() == MIMEApplicationJSON
 }
 
+func (r *Request) IsXML() bool {
+	ct := r.ContentType()
+	return ct == MIMEApplicationXML || ct == MIMETextXML
+}
+
 func (r *Request) IsForm() bool {
 	return r.ContentType() == MIMEApplicationForm
 }
@@ -76,6 +112,15 @@ func (r *Request) IsTLS() bool {
 	return r.TLS != nil
 }
 
+// OriginalPath returns the path of the request before a Mount stripped its
+// prefix.
+func (r *Request) OriginalPath() string {
+	if p := OriginalPathFromContext(r.Context()); p != "" {
+		return p
+	}
+	return r.URL.Path
+}
+
 func (r *Request) PathParams() PathParams {
 	return ParamsFromContext(r.Context())
 }
@@ -91,6 +136,38 @@ func (r *Request) PathParamID() string {
 	return r.PathParam("id")
 }
 
+func (r *Request) HostParams() PathParams {
+	return HostParamsFromContext(r.Context())
+}
+
+func (r *Request) HostParam(param string) string {
+	if s, ok := r.HostParams()[param]; ok && len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// PathParamValue returns the value of a typed param such as ":id<int>" as
+// parsed by its ParamType.
+func (r *Request) PathParamValue(param string) any {
+	return PathValuesFromContext(r.Context())[param]
+}
+
+func (r *Request) PathParamInt(param string) int {
+	v, _ := r.PathParamValue(param).(int)
+	return v
+}
+
+func (r *Request) PathParamUUID(param string) UUID {
+	v, _ := r.PathParamValue(param).(UUID)
+	return v
+}
+
+func (r *Request) PathParamTime(param string) time.Time {
+	v, _ := r.PathParamValue(param).(time.Time)
+	return v
+}
+
 func (r *Request) QueryParams() url.Values {
 	if r.query == nil {
 		r.query = r.URL.Query()
@@ -104,7 +181,11 @@ func (r *Request) QueryParam(name string) string {
 
 func (r *Request) FormValues() (url.Values, error) {
 	if r.IsMultipartForm() {
-		if err := r.ParseMultipartForm(defaultMaxMemory); err != nil {
+		maxMemory := r.maxMemory
+		if maxMemory <= 0 {
+			maxMemory = DefaultMaxMultipartMemory
+		}
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
 			return nil, err
 		}
 	} else if err := r.ParseForm(); err != nil {
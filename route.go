@@ -2,7 +2,11 @@ package wool
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cast"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 )
@@ -19,22 +23,121 @@ var DefaultMethods = []string{
 	http.MethodTrace,
 }
 
-var compiledRXPatterns = map[string]*regexp.Regexp{}
+var ErrRouteNotFound = errors.New("route not found")
 
 type PathParams map[string][]string
 
 type ctxPathParamsKey struct{}
 
+// segment is a parsed path segment of a route pattern. The value is the
+// literal text of a static segment or the name of a param, the constraint is
+// the "<type>|regex" part of a param as written in the pattern.
+type segment struct {
+	kind       nodeKind
+	value      string
+	constraint string
+	rx         *regexp.Regexp
+	typ        *ParamType
+	optional   bool
+}
+
 type route struct {
-	method   string
-	segments []string
-	wildcard bool
-	handler  Handler
+	method      string
+	host        string
+	version     string
+	pattern     string
+	name        string
+	segments    []segment
+	params      []string
+	types       []*ParamType
+	implicit    bool
+	handler     Handler
+	handlerName string
+	middlewares []string
+	versions    map[string]*route
+	owner       *Wool
+
+	// the parts of handler, kept to wrap it again in Route.Use
+	raw     Handler
+	routeMW []Middleware
+	groupMW []Middleware
+}
+
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Host        string   `json:"host,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares,omitempty"`
+}
+
+// Route is returned by Add and the method helpers and refers to every method
+// that was registered for a single pattern.
+type Route struct {
+	router *router
+	routes []*route
+}
+
+// Name gives the route a name which can be used to build its URL with
+// Wool.URL and Ctx.URLFor. Name panics when the name is already taken.
+func (r *Route) Name(name string) *Route {
+	if len(r.routes) > 0 {
+		r.router.name(name, r.routes)
+	}
+	return r
 }
 
-func (wool *Wool) Add(pattern string, handler Handler, methods ...string) {
+// Use adds route middlewares to every method of the route. They run inside the
+// middlewares of the group, after the route middlewares that were added
+// before. Like Add, Use is meant to be called before serving requests.
+func (r *Route) Use(mw ...Middleware) *Route {
+	if len(r.routes) > 0 && len(mw) > 0 {
+		r.router.use(r.routes, mw)
+	}
+	return r
+}
+
+// wrap wraps raw in the route and group middlewares of rt like Wool.wrap,
+// with the group middlewares that were in place when rt was added.
+func (rt *route) wrap() Handler {
+	handler := chain(chain(rt.raw, rt.routeMW), rt.groupMW)
+	return rt.owner.Error(rt.owner.Recover(handler))
+}
+
+// Add registers handler for pattern and methods, all methods are registered
+// when methods is empty. Route middlewares are added with Route.Use or passed
+// to the method helpers such as GET, they are wrapped inside the middlewares
+// of the group.
+//
+// A pattern consists of static segments, ":param" segments and an optional
+// catch-all as its last segment. A param can be constrained by a type and a
+// regex, ":param<type>|regex", and both have to match the whole segment.
+// A plain param never matches an empty segment. The last segment can be made
+// optional with "?", e.g. "/files/:name?" or "/files/:id<int>?|[0-9]{1,4}",
+// which registers both "/files" and "/files/:name". The catch-all "*name"
+// matches the rest of the path after the last slash, including an empty rest,
+// so "/static/*path" matches "/static/" and "/static/css/app.css" but not
+// "/static". A catch-all accepts the same constraints as a param, they have to
+// match the whole rest. "/..." is a catch-all named "...".
+//
+// A request path is resolved segment by segment in a fixed priority order that
+// does not depend on the registration order: static segments first, then
+// constrained params, then plain params, then constrained catch-alls and
+// finally the plain catch-all. Constrained siblings are tried with a type and
+// a regex first, then with a type only, then with a regex only, and ordered by
+// their constraint text within each of those. Add panics when the pattern is
+// ambiguous with an already registered route of the same method, i.e. when
+// both patterns only differ in the names of their params, unless they were
+// registered for different versions with Version.
+func (wool *Wool) Add(pattern string, handler Handler, methods ...string) *Route {
+	return wool.add(pattern, handler, methods)
+}
+
+func (wool *Wool) add(pattern string, handler Handler, methods []string, mw ...Middleware) *Route {
 	if contains(methods, http.MethodGet) && !contains(methods, http.MethodHead) {
-		methods = append(methods, http.MethodHead)
+		methods = append(methods[:len(methods):len(methods)], http.MethodHead)
 	}
 
 	if len(methods) == 0 {
@@ -42,75 +145,246 @@ func (wool *Wool) Add(pattern string, handler Handler, methods ...string) {
 	}
 
 	pattern = wool.prefix + pattern
+	segments := wool.router.parse(pattern)
 
-	for _, method := range methods {
-		route := route{
-			method:   strings.ToUpper(method),
-			segments: strings.Split(pattern, "/"),
-			wildcard: strings.HasSuffix(pattern, "/..."),
-			handler:  wool.wrap(handler),
-		}
+	variants := [][]segment{segments}
+	if last := segments[len(segments)-1]; last.optional {
+		variants = append(variants, segments[:len(segments)-1])
+	}
 
-		*wool.routes = append(*wool.routes, route)
+	middlewares := make([]string, 0, len(wool.middlewares)+len(mw))
+	for _, m := range wool.middlewares {
+		middlewares = append(middlewares, funcName(m))
+	}
+	for _, m := range mw {
+		middlewares = append(middlewares, funcName(m))
 	}
 
-	for _, segment := range strings.Split(pattern, "/") {
-		if strings.HasPrefix(segment, ":") {
-			_, rxPattern, containsRx := strings.Cut(segment, "|")
-			if containsRx {
-				compiledRXPatterns[rxPattern] = regexp.MustCompile(rxPattern)
+	wrapped := wool.wrap(handler, mw...)
+	handlerName := funcName(handler)
+	r := &Route{router: wool.router}
+
+	for _, method := range methods {
+		for i, variant := range variants {
+			rt := &route{
+				method:      strings.ToUpper(method),
+				host:        wool.host,
+				version:     wool.version,
+				pattern:     pattern,
+				segments:    variant,
+				implicit:    i > 0,
+				handler:     wrapped,
+				handlerName: handlerName,
+				middlewares: middlewares,
+				owner:       wool,
+				raw:         handler,
+				routeMW:     mw,
+				groupMW:     wool.middlewares[:len(wool.middlewares):len(wool.middlewares)],
 			}
+			for _, s := range variant {
+				if s.kind != nodeStatic {
+					rt.params = append(rt.params, s.value)
+					rt.types = append(rt.types, s.typ)
+				}
+			}
+			wool.router.add(wool.tree, rt)
+			r.routes = append(r.routes, rt)
 		}
 	}
 
 	wool.Log.Info("handler registered", "pattern", pattern, "methods", methods)
+
+	return r
+}
+
+// Routes returns every registered route in registration order.
+func (wool *Wool) Routes() []RouteInfo {
+	return wool.router.routeInfos()
+}
+
+// URL builds the path of the route registered under name. Params are used in
+// the order the params appear in the pattern, they are checked against the
+// ":param|regex" constraints and escaped.
+func (wool *Wool) URL(name string, params ...any) (string, error) {
+	rt, ok := wool.router.lookupName(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+	return rt.url(params...)
+}
+
+// routeParams are the params of a matched route. They are only built once the
+// whole path has matched and are stored in the request context at once.
+type routeParams struct {
+	params PathParams
+	values PathValues
+	host   PathParams
 }
 
 func ParamsFromContext(ctx context.Context) PathParams {
-	if params, ok := ctx.Value(ctxPathParamsKey{}).(PathParams); ok {
-		return params
+	if p, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok && p.params != nil {
+		return p.params
 	}
 	return PathParams{}
 }
 
 func ContextWithParams(ctx context.Context, params PathParams) context.Context {
-	return context.WithValue(ctx, ctxPathParamsKey{}, params)
+	p := &routeParams{params: params}
+	if prev, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok {
+		p.values = prev.values
+		p.host = prev.host
+	}
+	return context.WithValue(ctx, ctxPathParamsKey{}, p)
+}
+
+// hasPathParams reports whether ctx holds the params of an outer route.
+func hasPathParams(ctx context.Context) bool {
+	p, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams)
+	return ok && (p.params != nil || p.values != nil)
 }
 
-func (r *route) match(ctx context.Context, urlSegments []string) (context.Context, bool) {
-	if !r.wildcard && len(urlSegments) != len(r.segments) {
-		return ctx, false
+// withParams commits the values collected while matching r. The values are
+// copied because the slice is reused for the next request.
+func (r *route) withParams(ctx context.Context, values []string) (context.Context, error) {
+	values = append([]string(nil), values...)
+	p := &routeParams{params: make(PathParams, len(values))}
+	if prev, ok := ctx.Value(ctxPathParamsKey{}).(*routeParams); ok {
+		p.host = prev.host
 	}
 
-	params := ParamsFromContext(ctx)
+	for i, value := range values {
+		name := r.params[i]
+		if _, ok := p.params[name]; ok {
+			p.params[name] = append(p.params[name], value)
+		} else {
+			p.params[name] = values[i : i+1 : i+1]
+		}
 
-	for i, routeSegment := range r.segments {
-		if i > len(urlSegments)-1 {
-			return ctx, false
+		if typ := r.types[i]; typ != nil {
+			v, err := typ.Parse(value)
+			if err != nil {
+				return ctx, NewErrBadRequest(err)
+			}
+			if p.values == nil {
+				p.values = PathValues{}
+			}
+			p.values[name] = v
 		}
+	}
+
+	return context.WithValue(ctx, ctxPathParamsKey{}, p), nil
+}
 
-		if routeSegment == "..." {
-			params["..."] = []string{strings.Join(urlSegments[i:], "/")}
-			return ContextWithParams(ctx, params), true
+func (r *route) url(params ...any) (string, error) {
+	segments := make([]string, 0, len(r.segments))
+	i := 0
+
+	for _, s := range r.segments {
+		if s.kind == nodeStatic {
+			segments = append(segments, s.value)
+			continue
+		}
+
+		if i >= len(params) {
+			if s.optional {
+				break
+			}
+			return "", fmt.Errorf("route %s: missing value for param %s", r.name, s.value)
 		}
+		value, err := cast.ToStringE(params[i])
+		if err != nil {
+			return "", fmt.Errorf("route %s: param %s: %w", r.name, s.value, err)
+		}
+		i++
 
-		if routeSegment != "" && routeSegment[0] == ':' {
-			key, rxPattern, containsRx := strings.Cut(routeSegment[1:], "|")
+		if !s.match(value) {
+			return "", fmt.Errorf("route %s: value %q does not match param %s", r.name, value, s.value)
+		}
 
-			if (containsRx && compiledRXPatterns[rxPattern].MatchString(urlSegments[i])) ||
-				(!containsRx && urlSegments[i] != "") {
-				params[key] = append(params[key], urlSegments[i])
-				ctx = ContextWithParams(ctx, params)
-				continue
+		if s.kind == nodeWildcard {
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
 			}
+			segments = append(segments, strings.Join(parts, "/"))
+			break
+		}
+
+		segments = append(segments, url.PathEscape(value))
+	}
+
+	if i < len(params) {
+		return "", fmt.Errorf("route %s: too many params", r.name)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
 
-			return ctx, false
+func (s *segment) match(value string) bool {
+	if s.constraint == "" {
+		return s.kind == nodeWildcard || value != ""
+	}
+	if s.typ != nil && !s.typ.match(value) {
+		return false
+	}
+	return s.rx == nil || s.rx.MatchString(value)
+}
+
+func (r *router) parse(pattern string) []segment {
+	parts := strings.Split(pattern, "/")
+	segments := make([]segment, len(parts))
+
+	for i, part := range parts {
+		switch {
+		case part == "...":
+			segments[i] = segment{kind: nodeWildcard, value: part}
+		case strings.HasPrefix(part, "*"):
+			segments[i] = r.parseParam(nodeWildcard, part[1:])
+			if segments[i].value == "" {
+				segments[i].value = "..."
+			}
+		case strings.HasPrefix(part, ":"):
+			segments[i] = r.parseParam(nodeParam, part[1:])
+		default:
+			segments[i] = segment{kind: nodeStatic, value: part}
 		}
 
-		if urlSegments[i] != routeSegment {
-			return ctx, false
+		if i < len(parts)-1 && (segments[i].kind == nodeWildcard || segments[i].optional) {
+			panic(fmt.Sprintf("wool: %s of pattern %s must be the last segment", part, pattern))
 		}
 	}
 
-	return ctx, true
+	return segments
+}
+
+// parseParam parses the param segment "name<type>?|regex" where the type, the
+// optional marker and the regex can be omitted.
+func (r *router) parseParam(kind nodeKind, param string) segment {
+	s := segment{kind: kind}
+
+	name, rxPattern, containsRx := strings.Cut(param, "|")
+	if containsRx {
+		s.rx = regexp.MustCompile("^(?:" + rxPattern + ")$")
+		s.constraint = "|" + rxPattern
+	}
+
+	if kind == nodeParam && strings.HasSuffix(name, "?") {
+		s.optional = true
+		name = name[:len(name)-1]
+	}
+
+	if i := strings.IndexByte(name, '<'); i >= 0 && strings.HasSuffix(name, ">") {
+		typeName := name[i+1 : len(name)-1]
+		typ, ok := r.paramType(typeName)
+		if !ok {
+			panic(fmt.Sprintf("wool: unknown param type %q", typeName))
+		}
+		s.typ = &typ
+		s.constraint = name[i:] + s.constraint
+		name = name[:i]
+	}
+
+	s.value = name
+
+	return s
 }
@@ -0,0 +1,58 @@
+package wool
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// trace appends name to the X-Trace header before calling the next handler.
+func trace(name string) Middleware {
+	return func(next Handler) Handler {
+		return func(c Ctx) error {
+			c.Res().Header().Add("X-Trace", name)
+			return next(c)
+		}
+	}
+}
+
+func TestRouteMiddlewares(t *testing.T) {
+	w := newTestWool()
+	w.Use(trace("root"))
+	api := w.Group("/api")
+	api.Use(trace("group"))
+
+	api.Add("/add", echo("add"), http.MethodGet).Use(trace("route"))
+	api.GET("/get", echo("get"), trace("helper")).Use(trace("route"))
+	api.Add("/plain", echo("plain"))
+	// changing the group after Add does not change the route
+	api.Use(trace("late"))
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/add", "root,group,route"},
+		{http.MethodHead, "/api/add", "root,group,route"},
+		{http.MethodGet, "/api/get", "root,group,helper,route"},
+		{http.MethodPost, "/api/plain", "root,group"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := serve(w, tt.method, tt.path)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := strings.Join(rec.Header().Values("X-Trace"), ","); got != tt.want {
+				t.Errorf("middlewares = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, info := range w.Routes() {
+		if info.Pattern == "/api/get" && len(info.Middlewares) != 4 {
+			t.Errorf("%s %s: middlewares = %v, want 4", info.Method, info.Pattern, info.Middlewares)
+		}
+	}
+}
@@ -0,0 +1,512 @@
+package wool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type nodeKind uint8
+
+const (
+	nodeStatic nodeKind = iota
+	nodeParam
+	nodeWildcard
+)
+
+// node is a single path segment of a routing tree. Children are kept apart by
+// kind so that lookup can try them in priority order: static segments first,
+// then params with a type or regex constraint in registration order, then the
+// plain param, then constrained catch-alls and finally the plain catch-all.
+type node struct {
+	kind        nodeKind
+	constraint  *segment
+	static      map[string]*node
+	constrained []*node
+	param       *node
+	wildcards   []*node
+	wildcard    *node
+	route       *route
+	group       *Wool
+}
+
+// tree holds the routes of a single host scope with one root node per method.
+// The owner handles requests that match neither a route nor a group.
+type tree struct {
+	owner   *Wool
+	groups  node
+	roots   map[string]*node
+	methods []string
+}
+
+type router struct {
+	mu     sync.RWMutex
+	tree   *tree
+	exact  map[string]*host
+	hosts  []*host
+	routes []*route
+	names  map[string]*route
+	types  map[string]ParamType
+	pool   sync.Pool
+}
+
+func newRouter() *router {
+	r := &router{
+		tree:  newTree(nil),
+		exact: map[string]*host{},
+		names: map[string]*route{},
+		types: map[string]ParamType{},
+	}
+	for name, typ := range DefaultParamTypes {
+		r.types[name] = typ
+	}
+	r.pool.New = func() any {
+		values := make([]string, 0, 8)
+		return &values
+	}
+	return r
+}
+
+func newTree(owner *Wool) *tree {
+	return &tree{owner: owner, roots: map[string]*node{}}
+}
+
+func (r *router) add(t *tree, rt *route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	root, ok := t.roots[rt.method]
+	if !ok {
+		root = &node{}
+		t.roots[rt.method] = root
+		t.methods = append(t.methods, rt.method)
+	}
+	root.insert(rt)
+	r.routes = append(r.routes, rt)
+}
+
+func (r *router) use(routes []*route, mw []Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	first := routes[0]
+	routeMW := append(first.routeMW[:len(first.routeMW):len(first.routeMW)], mw...)
+	names := first.middlewares[:len(first.middlewares):len(first.middlewares)]
+	for _, m := range mw {
+		names = append(names, funcName(m))
+	}
+
+	for _, rt := range routes {
+		rt.routeMW, rt.middlewares = routeMW, names
+		rt.handler = rt.wrap()
+	}
+}
+
+func (r *router) name(name string, routes []*route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if other, ok := r.names[name]; ok {
+		panic(fmt.Sprintf("wool: route name %q of %s is already used by %s", name, routes[0].pattern, other.pattern))
+	}
+	r.names[name] = routes[0]
+	for _, rt := range routes {
+		rt.name = name
+	}
+}
+
+func (r *router) addGroup(group *Wool) {
+	segments := r.parse(group.prefix)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := &group.tree.groups
+	for i := range segments {
+		current = current.child(&segments[i])
+		if current.kind == nodeWildcard {
+			break
+		}
+	}
+	if current.group == nil {
+		current.group = group
+	}
+}
+
+// group returns the deepest group of t whose prefix matches path.
+func (r *router) group(t *tree, path string) *Wool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if group := t.groups.lookupGroup(path, 0); group != nil {
+		return group
+	}
+	return t.owner
+}
+
+func (r *router) find(t *tree, method, path string, values *[]string) *route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if root, ok := t.roots[method]; ok {
+		return root.lookup(path, 0, values)
+	}
+	return nil
+}
+
+// findFold looks up path ignoring the case of static segments and returns the
+// path with the case of the matched route.
+func (r *router) findFold(t *tree, method, path string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if root, ok := t.roots[method]; ok {
+		if fixed := root.lookupFold(path, 0, make([]byte, 0, len(path))); fixed != nil {
+			return string(fixed), true
+		}
+	}
+	return "", false
+}
+
+func (r *router) allowed(t *tree, method, path string, values *[]string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var methods []string
+	for _, m := range t.methods {
+		if m == method {
+			continue
+		}
+		*values = (*values)[:0]
+		if t.roots[m].lookup(path, 0, values) != nil {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+func (r *router) lookupName(name string) (*route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rt, ok := r.names[name]
+	return rt, ok
+}
+
+func (r *router) routeInfos() []RouteInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]RouteInfo, 0, len(r.routes))
+	for _, rt := range r.routes {
+		if rt.implicit {
+			continue
+		}
+		routes = append(routes, RouteInfo{
+			Method:      rt.method,
+			Host:        rt.host,
+			Version:     rt.version,
+			Pattern:     rt.pattern,
+			Name:        rt.name,
+			Handler:     rt.handlerName,
+			Middlewares: rt.middlewares,
+		})
+	}
+	return routes
+}
+
+func (r *router) acquireValues() *[]string {
+	return r.pool.Get().(*[]string)
+}
+
+func (r *router) releaseValues(values *[]string) {
+	*values = (*values)[:0]
+	r.pool.Put(values)
+}
+
+func (r *router) addType(name string, typ ParamType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.types[name] = typ
+}
+
+func (r *router) paramType(name string) (ParamType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	typ, ok := r.types[name]
+	return typ, ok
+}
+
+func (n *node) insert(rt *route) {
+	current := n
+	for i := range rt.segments {
+		current = current.child(&rt.segments[i])
+		if current.kind == nodeWildcard {
+			break
+		}
+	}
+	current.setRoute(rt)
+}
+
+// setRoute sets the route of a leaf. Routes registered for different versions
+// share the leaf through a route that only holds the versions, it is owned by
+// the unversioned route if there is one and by the first version otherwise.
+func (n *node) setRoute(rt *route) {
+	existing := n.route
+	switch {
+	case existing == nil && rt.version == "":
+		n.route = rt
+	case existing == nil:
+		n.route = &route{method: rt.method, pattern: rt.pattern, versions: map[string]*route{rt.version: rt}, owner: rt.owner}
+	case existing.versions == nil && rt.version != "":
+		n.route = &route{method: rt.method, pattern: rt.pattern, versions: map[string]*route{"": existing, rt.version: rt}, owner: existing.owner}
+	case existing.versions != nil && existing.versions[rt.version] == nil:
+		existing.versions[rt.version] = rt
+		if rt.version == "" {
+			existing.owner = rt.owner
+		}
+	default:
+		if existing.versions != nil {
+			existing = existing.versions[rt.version]
+		}
+		panic(fmt.Sprintf("wool: route %s %s is ambiguous with %s %s", rt.method, rt.pattern, existing.method, existing.pattern))
+	}
+}
+
+func (n *node) child(s *segment) *node {
+	switch s.kind {
+	case nodeWildcard:
+		if s.constraint != "" {
+			return constrainedChild(&n.wildcards, s)
+		}
+		if n.wildcard == nil {
+			n.wildcard = &node{kind: nodeWildcard}
+		}
+		return n.wildcard
+	case nodeParam:
+		if s.constraint != "" {
+			return constrainedChild(&n.constrained, s)
+		}
+		if n.param == nil {
+			n.param = &node{kind: nodeParam}
+		}
+		return n.param
+	default:
+		if child, ok := n.static[s.value]; ok {
+			return child
+		}
+		if n.static == nil {
+			n.static = map[string]*node{}
+		}
+		child := &node{kind: nodeStatic}
+		n.static[s.value] = child
+		return child
+	}
+}
+
+func constrainedChild(children *[]*node, s *segment) *node {
+	for _, child := range *children {
+		if child.constraint.constraint == s.constraint {
+			return child
+		}
+	}
+	child := &node{kind: s.kind, constraint: s}
+	i := sort.Search(len(*children), func(i int) bool {
+		return constraintLess(s, (*children)[i].constraint)
+	})
+	*children = append(*children, nil)
+	copy((*children)[i+1:], (*children)[i:])
+	(*children)[i] = child
+	return child
+}
+
+// constraintLess orders constrained siblings independently of the
+// registration order: a type with a regex comes before a type alone, which
+// comes before a regex alone. Equal ranks are ordered by their constraint.
+func constraintLess(a, b *segment) bool {
+	if ra, rb := constraintRank(a), constraintRank(b); ra != rb {
+		return ra < rb
+	}
+	return a.constraint < b.constraint
+}
+
+func constraintRank(s *segment) int {
+	switch {
+	case s.typ != nil && s.rx != nil:
+		return 0
+	case s.typ != nil:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// lookup matches path[start:] against the subtree of n. A start beyond the end
+// of the path means that every segment has been consumed.
+func (n *node) lookup(path string, start int, values *[]string) *route {
+	if start > len(path) {
+		return n.route
+	}
+
+	end := strings.IndexByte(path[start:], '/')
+	if end < 0 {
+		end = len(path)
+	} else {
+		end += start
+	}
+	segment := path[start:end]
+
+	if child, ok := n.static[segment]; ok {
+		if rt := child.lookup(path, end+1, values); rt != nil {
+			return rt
+		}
+	}
+
+	for _, child := range n.constrained {
+		if !child.constraint.match(segment) {
+			continue
+		}
+		if rt := child.lookupParam(path, segment, end+1, values); rt != nil {
+			return rt
+		}
+	}
+
+	if n.param != nil && segment != "" {
+		if rt := n.param.lookupParam(path, segment, end+1, values); rt != nil {
+			return rt
+		}
+	}
+
+	for _, child := range n.wildcards {
+		if child.route != nil && child.constraint.match(path[start:]) {
+			*values = append(*values, path[start:])
+			return child.route
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.route != nil {
+		*values = append(*values, path[start:])
+		return n.wildcard.route
+	}
+
+	return nil
+}
+
+func (n *node) lookupParam(path, segment string, next int, values *[]string) *route {
+	*values = append(*values, segment)
+	if rt := n.lookup(path, next, values); rt != nil {
+		return rt
+	}
+	*values = (*values)[:len(*values)-1]
+	return nil
+}
+
+func (n *node) lookupGroup(path string, start int) *Wool {
+	if start > len(path) {
+		return n.group
+	}
+
+	end := strings.IndexByte(path[start:], '/')
+	if end < 0 {
+		end = len(path)
+	} else {
+		end += start
+	}
+	segment := path[start:end]
+
+	if child, ok := n.static[segment]; ok {
+		if group := child.lookupGroup(path, end+1); group != nil {
+			return group
+		}
+	}
+
+	for _, child := range n.constrained {
+		if !child.constraint.match(segment) {
+			continue
+		}
+		if group := child.lookupGroup(path, end+1); group != nil {
+			return group
+		}
+	}
+
+	if n.param != nil && segment != "" {
+		if group := n.param.lookupGroup(path, end+1); group != nil {
+			return group
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.group != nil {
+		return n.wildcard.group
+	}
+
+	return n.group
+}
+
+func (n *node) lookupFold(path string, start int, fixed []byte) []byte {
+	if start > len(path) {
+		if n.route != nil {
+			return fixed
+		}
+		return nil
+	}
+
+	end := strings.IndexByte(path[start:], '/')
+	if end < 0 {
+		end = len(path)
+	} else {
+		end += start
+	}
+	segment := path[start:end]
+
+	next := func(s string) []byte {
+		b := append(fixed[:len(fixed):len(fixed)], s...)
+		if end < len(path) {
+			b = append(b, '/')
+		}
+		return b
+	}
+
+	if child, ok := n.static[segment]; ok {
+		if f := child.lookupFold(path, end+1, next(segment)); f != nil {
+			return f
+		}
+	}
+	for key, child := range n.static {
+		if key == segment || !strings.EqualFold(key, segment) {
+			continue
+		}
+		if f := child.lookupFold(path, end+1, next(key)); f != nil {
+			return f
+		}
+	}
+
+	for _, child := range n.constrained {
+		if !child.constraint.match(segment) {
+			continue
+		}
+		if f := child.lookupFold(path, end+1, next(segment)); f != nil {
+			return f
+		}
+	}
+
+	if n.param != nil && segment != "" {
+		if f := n.param.lookupFold(path, end+1, next(segment)); f != nil {
+			return f
+		}
+	}
+
+	for _, child := range n.wildcards {
+		if child.route != nil && child.constraint.match(path[start:]) {
+			return append(fixed, path[start:]...)
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.route != nil {
+		return append(fixed, path[start:]...)
+	}
+
+	return nil
+}
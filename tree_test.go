@@ -0,0 +1,200 @@
+package wool
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+)
+
+func newTestWool() *Wool {
+	return New(slog.New(slog.NewTextHandler(io.Discard)))
+}
+
+// echo writes the name of the route followed by its sorted path params.
+func echo(name string) Handler {
+	return func(c Ctx) error {
+		params := c.Req().PathParams()
+		keys := make([]string, 0, len(params))
+		for k := range params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var b strings.Builder
+		b.WriteString(name)
+		for _, k := range keys {
+			fmt.Fprintf(&b, " %s=%s", k, strings.Join(params[k], ","))
+		}
+		return c.String(http.StatusOK, "%s", b.String())
+	}
+}
+
+func serve(w *Wool, method, target string) *httptest.ResponseRecorder {
+	return serveRequest(w, httptest.NewRequest(method, target, nil))
+}
+
+func serveRequest(w *Wool, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	w.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestLookupPriority(t *testing.T) {
+	w := newTestWool()
+	// registered in reverse priority order on purpose
+	w.GET("/a/*rest", echo("catch-all"))
+	w.GET("/a/*rest|.+\\.css", echo("constrained catch-all"))
+	w.GET("/a/:name", echo("param"))
+	w.GET("/a/:id<int>", echo("constrained"))
+	w.GET("/a/static", echo("static"))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/a/static", "static"},
+		{"/a/42", "constrained id=42"},
+		{"/a/foo", "param name=foo"},
+		{"/a/css/app.css", "constrained catch-all rest=css/app.css"},
+		{"/a/css/app.js", "catch-all rest=css/app.js"},
+		{"/a/", "catch-all rest="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(w, http.MethodGet, tt.path)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupParamRollback(t *testing.T) {
+	w := newTestWool()
+	w.GET("/b/:x/end", echo("end"))
+	w.GET("/b/*rest", echo("rest"))
+	w.GET("/c/:a/:b/x", echo("x"))
+	w.GET("/c/:a/*r", echo("r"))
+	w.GET("/d/:a<int>/x", echo("int"))
+	w.GET("/d/:b/y", echo("param"))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/b/foo/end", "end x=foo"},
+		{"/b/foo/bar", "rest rest=foo/bar"},
+		{"/c/1/2/x", "x a=1 b=2"},
+		{"/c/1/2/y", "r a=1 r=2/y"},
+		{"/d/1/x", "int a=1"},
+		{"/d/1/y", "param b=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(w, http.MethodGet, tt.path)
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupConstrainedOrder(t *testing.T) {
+	patterns := []struct {
+		pattern string
+		name    string
+	}{
+		{"/a/:x|[0-9]+", "regex"},
+		{"/a/:y<int>", "type"},
+		{"/a/:z<int>|[0-9]{2}", "both"},
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/a/12", "both z=12"},
+		{"/a/123", "type y=123"},
+		{"/a/007", "type y=007"},
+	}
+
+	for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}, {1, 0, 2}} {
+		w := newTestWool()
+		for _, i := range order {
+			w.GET(patterns[i].pattern, echo(patterns[i].name))
+		}
+		for _, tt := range tests {
+			if got := serve(w, http.MethodGet, tt.path).Body.String(); got != tt.want {
+				t.Errorf("order %v: %s = %q, want %q", order, tt.path, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	w := newTestWool()
+	w.GET("/r/:id", echo("get"))
+	w.POST("/r/:id", echo("post"))
+	w.DELETE("/r/:id<int>", echo("delete"))
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{http.MethodPut, "/r/1", http.StatusMethodNotAllowed, "GET,HEAD,POST,DELETE,OPTIONS"},
+		{http.MethodPut, "/r/x", http.StatusMethodNotAllowed, "GET,HEAD,POST,OPTIONS"},
+		{http.MethodOptions, "/r/1", http.StatusNoContent, "GET,HEAD,POST,DELETE,OPTIONS"},
+		{http.MethodPut, "/s", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := serve(w, tt.method, tt.path)
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow = %q, want %q", got, tt.allow)
+			}
+		})
+	}
+}
+
+func TestAddAmbiguous(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  string
+		second string
+		panics bool
+	}{
+		{"param names", "/p/:a", "/p/:b", true},
+		{"constraint", "/p/:a<int>", "/p/:b<int>", true},
+		{"catch-all names", "/p/*a", "/p/*b", true},
+		{"optional", "/p/:a?", "/p", true},
+		{"different constraints", "/p/:a<int>", "/p/:b|[0-9]+", false},
+		{"param and constraint", "/p/:a", "/p/:b<int>", false},
+		{"param and catch-all", "/p/:a", "/p/*b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWool()
+			w.GET(tt.first, echo("first"))
+
+			defer func() {
+				if r := recover(); (r != nil) != tt.panics {
+					t.Errorf("panic = %v, want panic %t", r, tt.panics)
+				}
+			}()
+			w.GET(tt.second, echo("second"))
+		})
+	}
+}
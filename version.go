@@ -0,0 +1,168 @@
+package wool
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionResolver extracts the requested API version from a request. Vary is
+// the request header the version depends on, if any.
+type VersionResolver struct {
+	Vary    string
+	Resolve func(r *Request) string
+}
+
+// Deprecation describes a deprecated API version. Date is the date of the
+// deprecation, Sunset the date the version stops working and Link a page
+// describing the deprecation. All of them are optional.
+type Deprecation struct {
+	Date   time.Time
+	Sunset time.Time
+	Link   string
+}
+
+// Versioning chooses between the handlers that were registered for the same
+// pattern with Wool.Version. The first non-empty version returned by the
+// Resolvers is used and Default when there is none. A request for a version
+// that is not registered is answered with 400 Bad Request, unless a handler was
+// registered for the pattern without a version.
+type Versioning struct {
+	Resolvers  []VersionResolver
+	Default    string
+	Deprecated map[string]Deprecation
+}
+
+func WithVersioning(v Versioning) Option {
+	return func(w *Wool) {
+		w.Versioning = v
+	}
+}
+
+// HeaderVersion reads the version from a request header such as
+// "API-Version: 2".
+func HeaderVersion(header string) VersionResolver {
+	return VersionResolver{
+		Vary: header,
+		Resolve: func(r *Request) string {
+			return strings.TrimSpace(r.Header.Get(header))
+		},
+	}
+}
+
+// QueryVersion reads the version from a query param such as "?version=2".
+func QueryVersion(param string) VersionResolver {
+	return VersionResolver{
+		Resolve: func(r *Request) string {
+			return r.QueryParam(param)
+		},
+	}
+}
+
+// MediaTypeVersion reads the version from the Accept header, either from a
+// vendor media type such as "application/vnd.acme.v2+json" or from a
+// "version" param such as "application/json; version=2". An empty vendor
+// accepts every vendor.
+func MediaTypeVersion(vendor string) VersionResolver {
+	return VersionResolver{
+		Vary: HeaderAccept,
+		Resolve: func(r *Request) string {
+			for _, mediaRange := range strings.Split(r.Header.Get(HeaderAccept), ",") {
+				mediaType, params, _ := strings.Cut(mediaRange, ";")
+				for _, param := range strings.Split(params, ";") {
+					if key, value, ok := strings.Cut(param, "="); ok && strings.EqualFold(strings.TrimSpace(key), "version") {
+						return strings.Trim(strings.TrimSpace(value), `"`)
+					}
+				}
+
+				_, subtype, _ := strings.Cut(strings.TrimSpace(mediaType), "/")
+				subtype, _, _ = strings.Cut(subtype, "+")
+				if !strings.HasPrefix(subtype, "vnd.") {
+					continue
+				}
+				subtype = subtype[len("vnd."):]
+				if vendor != "" {
+					if !strings.HasPrefix(subtype, vendor+".") {
+						continue
+					}
+					subtype = subtype[len(vendor)+1:]
+				} else if i := strings.LastIndexByte(subtype, '.'); i >= 0 {
+					subtype = subtype[i+1:]
+				}
+				if len(subtype) > 1 && subtype[0] == 'v' {
+					return subtype[1:]
+				}
+			}
+			return ""
+		},
+	}
+}
+
+// Version creates a sub-router whose routes are registered for version. The
+// same pattern can be registered for several versions, the Versioning of the
+// Wool serving the request chooses between them.
+func (wool *Wool) Version(version string, fn ...func(*Wool)) *Wool {
+	v := *wool
+	v.version = version
+	v.middlewares = append([]Middleware(nil), wool.middlewares...)
+
+	for _, f := range fn {
+		f(&v)
+	}
+	return &v
+}
+
+func (v Versioning) route(c Ctx, versions map[string]*route) (*route, error) {
+	header := c.Res().Header()
+	for _, resolver := range v.Resolvers {
+		if resolver.Vary != "" && !containsToken(header.Values(HeaderVary), resolver.Vary) {
+			header.Add(HeaderVary, resolver.Vary)
+		}
+	}
+
+	version := ""
+	for _, resolver := range v.Resolvers {
+		if version = resolver.Resolve(c.Req()); version != "" {
+			break
+		}
+	}
+	if version == "" {
+		version = v.Default
+	}
+
+	rt, ok := versions[version]
+	if !ok {
+		if rt, ok = versions[""]; !ok {
+			return nil, NewErrBadRequest(fmt.Errorf("unsupported API version %q", version))
+		}
+	}
+
+	if d, ok := v.Deprecated[rt.version]; ok && rt.version != "" {
+		if d.Date.IsZero() {
+			header.Set(HeaderDeprecation, "true")
+		} else {
+			header.Set(HeaderDeprecation, "@"+strconv.FormatInt(d.Date.Unix(), 10))
+		}
+		if !d.Sunset.IsZero() {
+			header.Set(HeaderSunset, d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Link != "" {
+			header.Add(HeaderLink, "<"+d.Link+`>; rel="deprecation"`)
+		}
+	}
+
+	return rt, nil
+}
+
+func containsToken(values []string, token string) bool {
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,10 +2,12 @@ package wool
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"github.com/gowool/wool/render"
 	"golang.org/x/exp/slog"
+	"html/template"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -29,16 +31,36 @@ var (
 		return c.NoContent()
 	}
 
+	// DefaultErrorHandler responds with text unless the client prefers one of
+	// the media types of the encoders, see Wool.RegisterEncoder. It falls back
+	// to text when the error cannot be encoded.
 	DefaultErrorHandler = func(c Ctx, err *Error) error {
-		switch c.NegotiateFormat(MIMETextPlain, MIMETextHTML, MIMEApplicationJSON) {
-		case MIMEApplicationJSON:
-			return c.JSON(err.Code, err)
+		var encodeErr error
+
+		offered := append([]string{MIMETextPlain, MIMETextHTML}, c.EncoderTypes()...)
+		switch c.NegotiateFormat(offered...) {
+		case MIMETextPlain, MIMETextHTML, "":
 		default:
-			if c.Debug() {
-				return c.String(err.Code, "code=%d, message=%v, data=%v, developer_message=%s", err.Code, err.Message, err.Data, err.Developer)
+			// encoders may ignore the json tags, the internal error is not meant
+			// for the client
+			public := *err
+			public.Internal = nil
+
+			if encodeErr = c.Encode(err.Code, &public); encodeErr == nil {
+				return nil
 			}
-			return c.String(err.Code, "code=%d, message=%v, data=%v", err.Code, err.Message, err.Data)
 		}
+
+		var textErr error
+		if c.Debug() {
+			textErr = c.String(err.Code, "code=%d, message=%v, data=%v, developer_message=%s", err.Code, err.Message, err.Data, err.Developer)
+		} else {
+			textErr = c.String(err.Code, "code=%d, message=%v, data=%v", err.Code, err.Message, err.Data)
+		}
+		if textErr != nil {
+			return textErr
+		}
+		return encodeErr
 	}
 
 	DefaultErrorTransform = func(err error) *Error {
@@ -59,20 +81,31 @@ type (
 )
 
 type Wool struct {
-	Log              *slog.Logger
-	NewCtxFunc       func(wool *Wool, r *http.Request, w http.ResponseWriter) Ctx
-	HTMLRender       render.HTMLRender
-	NotFoundHandler  Handler
-	MethodNotAllowed Handler
-	OptionsHandler   Handler
-	ErrorHandler     ErrorHandler
-	ErrorTransform   ErrorTransform
-	AfterServe       AfterServe
-	Validator        Validator
-	middlewares      []Middleware
-	ctxPool          *sync.Pool
-	routes           *[]route
-	prefix           string
+	Log                *slog.Logger
+	NewCtxFunc         func(wool *Wool, r *http.Request, w http.ResponseWriter) Ctx
+	HTMLRender         render.HTMLRender
+	NotFoundHandler    Handler
+	MethodNotAllowed   Handler
+	OptionsHandler     Handler
+	ErrorHandler       ErrorHandler
+	ErrorTransform     ErrorTransform
+	AfterServe         AfterServe
+	Validator          Validator
+	Redirect           RedirectConfig
+	Versioning         Versioning
+	XMLHeader          string
+	JSONBinding        JSONBinding
+	BindLimits         BindLimits
+	MaxMultipartMemory int64
+	middlewares        []Middleware
+	decoders           *decoders
+	encoders           *encoders
+	ctxPool            *sync.Pool
+	router             *router
+	tree               *tree
+	host               string
+	version            string
+	prefix             string
 }
 
 func ToHandler(handler http.Handler) Handler {
@@ -150,6 +183,22 @@ func WithValidator(v Validator) Option {
 	}
 }
 
+// WithXMLHeader sets the header written before XML documents, an empty header
+// omits it.
+func WithXMLHeader(header string) Option {
+	return func(w *Wool) {
+		w.XMLHeader = header
+	}
+}
+
+// WithMaxMultipartMemory sets the number of bytes of a multipart form that are
+// kept in memory while parsing it, larger files are stored in temporary files.
+func WithMaxMultipartMemory(maxMemory int64) Option {
+	return func(w *Wool) {
+		w.MaxMultipartMemory = maxMemory
+	}
+}
+
 func WithMiddleware(mw ...Middleware) Option {
 	return func(w *Wool) {
 		w.Use(mw...)
@@ -162,24 +211,38 @@ func New(logger *slog.Logger, options ...Option) *Wool {
 	}
 
 	wool := &Wool{
-		Log:              logger,
-		NewCtxFunc:       NewCtx,
-		HTMLRender:       &render.HTMLEngine{},
-		NotFoundHandler:  DefaultNotFoundHandler,
-		MethodNotAllowed: DefaultMethodNotAllowed,
-		OptionsHandler:   DefaultOptionsHandler,
-		ErrorHandler:     DefaultErrorHandler,
-		ErrorTransform:   DefaultErrorTransform,
-		Validator:        NewValidator(),
-		ctxPool:          &sync.Pool{},
-		routes:           &[]route{},
+		Log:                logger,
+		NewCtxFunc:         NewCtx,
+		HTMLRender:         &render.HTMLEngine{},
+		NotFoundHandler:    DefaultNotFoundHandler,
+		MethodNotAllowed:   DefaultMethodNotAllowed,
+		OptionsHandler:     DefaultOptionsHandler,
+		ErrorHandler:       DefaultErrorHandler,
+		ErrorTransform:     DefaultErrorTransform,
+		Validator:          NewValidator(),
+		XMLHeader:          xml.Header,
+		MaxMultipartMemory: DefaultMaxMultipartMemory,
+		ctxPool:            &sync.Pool{},
+		router:             newRouter(),
+		decoders:           newDecoders(),
 	}
+	wool.encoders = newEncoders(wool)
+	wool.tree = wool.router.tree
+	wool.tree.owner = wool
 	wool.ctxPool.New = func() any {
 		return wool.NewCtx(nil, nil)
 	}
 	for _, opt := range options {
 		opt(wool)
 	}
+	if engine, ok := wool.HTMLRender.(*render.HTMLEngine); ok {
+		if engine.FuncMap == nil {
+			engine.FuncMap = template.FuncMap{}
+		}
+		if _, ok = engine.FuncMap["url"]; !ok {
+			engine.FuncMap["url"] = wool.URL
+		}
+	}
 	return wool
 }
 
@@ -195,10 +258,22 @@ func (wool *Wool) Use(mw ...Middleware) {
 	wool.middlewares = append(wool.middlewares, mw...)
 }
 
-func (wool *Wool) Group(pattern string, fn func(*Wool)) {
-	mm := *wool
-	mm.prefix += pattern
-	fn(&mm)
+// Group creates a sub-router for the pattern prefix. The group starts with a
+// copy of the middlewares and handlers of its parent, changing them later on
+// either side does not affect the other. The NotFoundHandler,
+// MethodNotAllowed and OptionsHandler of the deepest group matching the
+// request path are used when no route matches.
+func (wool *Wool) Group(pattern string, fn ...func(*Wool)) *Wool {
+	group := *wool
+	group.prefix += pattern
+	group.middlewares = append([]Middleware(nil), wool.middlewares...)
+
+	wool.router.addGroup(&group)
+
+	for _, f := range fn {
+		f(&group)
+	}
+	return &group
 }
 
 func (wool *Wool) AcquireCtx() Ctx {
@@ -286,40 +361,76 @@ func (wool *Wool) Recover(next Handler) Handler {
 }
 
 func (wool *Wool) serve(c Ctx) error {
-	urlSegments := strings.Split(c.Req().URL.Path, "/")
-	allowedMethods := make([]string, 0, len(DefaultMethods))
-
-	for _, route := range *wool.routes {
-		ctx, ok := route.match(c.Req().Context(), urlSegments)
-		if ok {
-			if c.Req().Method == route.method {
-				c.SetReq(c.Req().WithContext(ctx))
-				return route.handler(c)
+	method, path := c.Req().Method, c.Req().URL.Path
+
+	if wool.Redirect.CleanPath {
+		if cleaned := cleanPath(path); cleaned != path {
+			return wool.redirect(c, cleaned)
+		}
+	}
+
+	t, hostParams := wool.router.matchHost(c.Req().Host)
+	if hostParams != nil {
+		c.SetReq(c.Req().WithContext(ContextWithHostParams(c.Req().Context(), hostParams)))
+	}
+
+	values := wool.router.acquireValues()
+	defer wool.router.releaseValues(values)
+
+	if route := wool.router.find(t, method, path, values); route != nil {
+		if route.versions != nil {
+			var err error
+			owner := route.owner
+			if route, err = wool.Versioning.route(c, route.versions); err != nil {
+				return owner.wrap(func(Ctx) error { return err })(c)
 			}
-			if !contains(allowedMethods, route.method) {
-				allowedMethods = append(allowedMethods, route.method)
+		}
+		// a mounted app replaces the params of the outer route even when its
+		// own route has none
+		if len(*values) > 0 || hasPathParams(c.Req().Context()) {
+			ctx, err := route.withParams(c.Req().Context(), *values)
+			if err != nil {
+				return route.owner.wrap(func(Ctx) error { return err })(c)
 			}
+			c.SetReq(c.Req().WithContext(ctx))
 		}
+		return route.handler(c)
 	}
 
-	if len(allowedMethods) > 0 {
-		c.Res().Header().Set("Allow", strings.Join(append(allowedMethods, http.MethodOptions), ","))
-		if c.Req().Method == http.MethodOptions {
-			return wool.wrap(wool.OptionsHandler)(c)
+	if fixed := wool.canonicalPath(t, method, path, values); fixed != "" {
+		return wool.redirect(c, fixed)
+	}
+
+	group := wool.router.group(t, path)
+
+	if allowedMethods := wool.router.allowed(t, method, path, values); len(allowedMethods) > 0 {
+		if !contains(allowedMethods, http.MethodOptions) {
+			allowedMethods = append(allowedMethods, http.MethodOptions)
 		}
-		return wool.wrap(wool.MethodNotAllowed)(c)
+		c.Res().Header().Set("Allow", strings.Join(allowedMethods, ","))
+		if method == http.MethodOptions {
+			return group.wrap(group.OptionsHandler)(c)
+		}
+		return group.wrap(group.MethodNotAllowed)(c)
 	}
 
-	return wool.wrap(wool.NotFoundHandler)(c)
+	return group.wrap(group.NotFoundHandler)(c)
 }
 
-func (wool *Wool) wrap(handler Handler) Handler {
-	for i := len(wool.middlewares) - 1; i >= 0; i-- {
-		handler = wool.middlewares[i](handler)
-	}
+func (wool *Wool) wrap(handler Handler, mw ...Middleware) Handler {
+	handler = chain(handler, mw)
+	handler = chain(handler, wool.middlewares)
 
 	handler = wool.Recover(handler)
 	handler = wool.Error(handler)
 
 	return handler
 }
+
+// chain wraps handler in mw, the first middleware is the outermost.
+func chain(handler Handler, mw []Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](handler)
+	}
+	return handler
+}
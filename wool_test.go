@@ -0,0 +1,62 @@
+package wool
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestRouteErrorsUseOwner(t *testing.T) {
+	w := newTestWool()
+	w.Versioning = Versioning{Resolvers: []VersionResolver{HeaderVersion("X-Version")}}
+	w.RegisterParamType("even", ParamType{
+		Match: func(string) bool { return true },
+		Parse: func(value string) (any, error) {
+			if n, err := strconv.Atoi(value); err != nil || n%2 != 0 {
+				return nil, errors.New("not even")
+			}
+			return value, nil
+		},
+	})
+
+	api := w.Group("/api")
+	api.ErrorHandler = func(c Ctx, err *Error) error {
+		return c.String(err.Code, "api: %v", err.Message)
+	}
+	api.Use(func(next Handler) Handler {
+		return func(c Ctx) error {
+			c.Res().Header().Set("X-Group", "api")
+			return next(c)
+		}
+	})
+	api.GET("/items/:id<even>", echo("item"))
+	api.Version("v1").GET("/things", echo("v1"))
+
+	tests := []struct {
+		name    string
+		path    string
+		version string
+	}{
+		{"param", "/api/items/3", ""},
+		{"version", "/api/things", "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set("X-Version", tt.version)
+			rec := serveRequest(w, r)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if got := rec.Header().Get("X-Group"); got != "api" {
+				t.Errorf("X-Group = %q, want %q", got, "api")
+			}
+			if got := rec.Body.String(); len(got) < 5 || got[:5] != "api: " {
+				t.Errorf("body = %q, want the error handler of the group", got)
+			}
+		})
+	}
+}